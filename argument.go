@@ -0,0 +1,271 @@
+package pgxpoolgo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgtype"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+/*
+The codes below is based on `pgxmock` from `github.com/pashagolub/pgxmock`
+*/
+
+// Argument matches an actual argument of a call. It can be expected in place of a value by WithArgs, WithQueued,
+// WithBatch and WithRows.
+type Argument interface {
+	Match(actual interface{}) bool
+}
+
+// argument is an Argument described by name in the mismatches.
+type argument struct {
+	name  string
+	match func(actual interface{}) bool
+}
+
+func (a *argument) Match(actual interface{}) bool {
+	return a.match(actual)
+}
+
+func (a *argument) String() string {
+	return a.name
+}
+
+// AnyArg matches any argument, NULL included.
+func AnyArg() Argument {
+	return &argument{name: "AnyArg()", match: func(interface{}) bool {
+		return true
+	}}
+}
+
+// AnyOfType matches any argument of the type T, or implementing T when it is an interface.
+func AnyOfType[T any]() Argument {
+	return &argument{name: fmt.Sprintf("AnyOfType[%s]()", reflect.TypeOf((*T)(nil)).Elem()), match: func(actual interface{}) bool {
+		_, ok := actual.(T)
+		return ok
+	}}
+}
+
+// ArgThat matches an argument of the type T for which f returns true. Integers, strings and pgtype values are
+// converted to T when they are of another type, like int or pgtype.Int8 for int64.
+func ArgThat[T any](f func(T) bool) Argument {
+	return &argument{name: fmt.Sprintf("ArgThat(func(%s) bool)", reflect.TypeOf((*T)(nil)).Elem()), match: func(actual interface{}) bool {
+		v, ok := actual.(T)
+		if !ok {
+			v, ok = argValue(actual).(T)
+		}
+		return ok && f(v)
+	}}
+}
+
+// TimeWithin matches a time at most d before or after the time it is matched at, like a time.Now() argument. The
+// time can be a time.Time, a pgtype value, or a string PostgreSQL reads as a timestamp.
+func TimeWithin(d time.Duration) Argument {
+	return &argument{name: fmt.Sprintf("TimeWithin(%s)", d), match: func(actual interface{}) bool {
+		t, ok := argTime(actual)
+		if !ok {
+			return false
+		}
+		delta := time.Since(t)
+		return delta <= d && delta >= -d
+	}}
+}
+
+// JSONEq matches an argument whose JSON is equal to expected, whatever the order of the keys of its objects. The
+// argument can be JSON text as a string or []byte, a pgtype.JSON or pgtype.JSONB, or a value encoded to JSON, like
+// a map or a struct. It panics when expected is not valid JSON.
+func JSONEq(expected string) Argument {
+	var want interface{}
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		panic(fmt.Sprintf("JSONEq: invalid JSON %s: %s", expected, err))
+	}
+	return &argument{name: fmt.Sprintf("JSONEq(%s)", expected), match: func(actual interface{}) bool {
+		got, ok := argJSON(actual)
+		return ok && reflect.DeepEqual(want, got)
+	}}
+}
+
+var uuidV4 = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+// UUIDv4 matches a random UUID, as a string, a [16]byte array like uuid.UUID, or a pgtype.UUID.
+func UUIDv4() Argument {
+	return &argument{name: "UUIDv4()", match: func(actual interface{}) bool {
+		v := argValue(actual)
+		if s, ok := v.(string); ok {
+			return uuidV4.MatchString(s)
+		}
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Array || rv.Len() != 16 || rv.Type().Elem().Kind() != reflect.Uint8 {
+			return false
+		}
+		return rv.Index(6).Uint()>>4 == 4 && rv.Index(8).Uint()>>6 == 2
+	}}
+}
+
+// argsMatches matches the expected arguments of an expectation against the actual arguments of a call. Nil expected
+// arguments match any actual arguments. Every argument which does not match is reported, by its position.
+func argsMatches(expected, actual []interface{}) error {
+	if expected == nil {
+		return nil
+	}
+	if len(actual) != len(expected) {
+		return fmt.Errorf("expected %d, but got %d arguments", len(expected), len(actual))
+	}
+	var mismatches []string
+	for i, arg := range actual {
+		if !argMatches(expected[i], arg) {
+			mismatches = append(mismatches, fmt.Sprintf("argument %d expected %s does not match actual [%T - %+v]", i, argString(expected[i]), arg, arg))
+		}
+	}
+	if len(mismatches) > 0 {
+		return errors.New(strings.Join(mismatches, "; "))
+	}
+	return nil
+}
+
+// argMatches matches an expected argument, which can be an Argument, against an actual argument. Values are equal
+// when they are deeply equal, or when they are equal once typed alike by argValue.
+func argMatches(expected, actual interface{}) bool {
+	if a, ok := expected.(Argument); ok {
+		return a.Match(actual)
+	}
+	if reflect.DeepEqual(expected, actual) {
+		return true
+	}
+	e, a := argValue(expected), argValue(actual)
+	if et, ok := e.(time.Time); ok {
+		at, ok := a.(time.Time)
+		return ok && et.Equal(at)
+	}
+	return reflect.DeepEqual(e, a)
+}
+
+func isArgument(v interface{}) bool {
+	_, ok := v.(Argument)
+	return ok
+}
+
+func argString(expected interface{}) string {
+	if isArgument(expected) {
+		return fmt.Sprintf("[%v]", expected)
+	}
+	return fmt.Sprintf("[%T - %+v]", expected, expected)
+}
+
+// argValue returns the value an argument is compared by: integers as int64, floats as float64, strings and byte
+// slices of named types as string and []byte, the Go value of a pgtype value, like int64 for pgtype.Int8, and the
+// value a pointer points to.
+func argValue(v interface{}) interface{} {
+	if get, ok := pgValue(v); ok {
+		if _, undefined := get.(undefinedValue); undefined {
+			return get
+		}
+		if get == nil || reflect.TypeOf(get) == reflect.TypeOf(v) || reflect.PtrTo(reflect.TypeOf(get)) == reflect.TypeOf(v) {
+			return get
+		}
+		return argValue(get)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		return argValue(rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u := rv.Uint(); u <= math.MaxInt64 {
+			return int64(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Bytes()
+		}
+	}
+	return v
+}
+
+// undefinedValue is the value of a pgtype value of the type typ whose status is Undefined, which only equals the
+// undefined values of the same type.
+type undefinedValue struct {
+	typ reflect.Type
+}
+
+// pgValue returns the Go value of a pgtype value, which implements pgtype.Value by its pointer, like pgtype.Int8.
+func pgValue(v interface{}) (interface{}, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, false
+	}
+	value, ok := v.(pgtype.Value)
+	if !ok {
+		if rv.Kind() != reflect.Struct {
+			return nil, false
+		}
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		if value, ok = ptr.Interface().(pgtype.Value); !ok {
+			return nil, false
+		}
+	}
+	get := value.Get()
+	if status, ok := get.(pgtype.Status); ok && status == pgtype.Undefined {
+		typ := reflect.TypeOf(v)
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		return undefinedValue{typ: typ}, true
+	}
+	return get, true
+}
+
+// argTime returns the time of an argument, decoding a string the way PostgreSQL reads a timestamp.
+func argTime(v interface{}) (time.Time, bool) {
+	switch v := argValue(v).(type) {
+	case time.Time:
+		return v, true
+	case string:
+		ci := pgtype.NewConnInfo()
+		dt, _ := ci.DataTypeForOID(pgtype.TimestamptzOID)
+		value, err := fixtureValue(ci, dt, v)
+		if err != nil {
+			return time.Time{}, false
+		}
+		t, ok := value.(time.Time)
+		return t, ok
+	}
+	return time.Time{}, false
+}
+
+// argJSON returns the decoded JSON of an argument, which is JSON text or a value encoded to JSON.
+func argJSON(v interface{}) (interface{}, bool) {
+	var data []byte
+	switch value := argValue(v).(type) {
+	case nil:
+		return nil, false
+	case string:
+		data = []byte(value)
+	case []byte:
+		data = value
+	default:
+		var err error
+		if data, err = json.Marshal(value); err != nil {
+			return nil, false
+		}
+	}
+	var got interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		return nil, false
+	}
+	return got, true
+}
@@ -0,0 +1,103 @@
+package pgxpoolgo_test
+
+import (
+	"context"
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func argumentInsertEvent(ctx context.Context, pool pgxpoolgo.Pool, id string, userID interface{}, payload interface{}) error {
+	_, err := pool.Exec(ctx, `INSERT INTO events (id, user_id, payload, created_at) VALUES ($1, $2, $3, $4)`, id, userID, payload, time.Now())
+	return err
+}
+
+func TestArgument_OK(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockPool.ExpectExec(`INSERT INTO events (id, user_id, payload, created_at) VALUES ($1, $2, $3, $4)`).
+		WithArgs(pgxpoolgo.UUIDv4(), int64(7), pgxpoolgo.JSONEq(`{"b": [1, 2], "a": "x"}`), pgxpoolgo.TimeWithin(time.Minute)).
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("INSERT", 1))
+	mockPool.ExpectExec(`INSERT INTO events (id, user_id, payload, created_at) VALUES ($1, $2, $3, $4)`).
+		WithArgs(pgxpoolgo.AnyArg(), pgxpoolgo.AnyOfType[pgtype.Int8](), pgxpoolgo.ArgThat(func(s string) bool {
+			return strings.HasPrefix(s, "{")
+		}), pgxpoolgo.AnyOfType[time.Time]()).
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("INSERT", 1))
+
+	err := argumentInsertEvent(ctx, mockPool, "f47ac10b-58cc-4372-a567-0e02b2c3d479", 7, map[string]interface{}{"a": "x", "b": []int{1, 2}})
+	assert.Nil(t, err)
+	err = argumentInsertEvent(ctx, mockPool, "", pgtype.Int8{Int: 7, Status: pgtype.Present}, `{}`)
+	assert.Nil(t, err)
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestArgument_TypedEquality(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockPool.ExpectExec(`INSERT INTO events (id, user_id, payload, created_at) VALUES ($1, $2, $3, $4)`).
+		WithArgs("e1", 7, nil, pgxpoolgo.AnyArg())
+	mockPool.ExpectExec(`INSERT INTO events (id, user_id, payload, created_at) VALUES ($1, $2, $3, $4)`).
+		WithArgs("e2", pgtype.Int8{Int: 8, Status: pgtype.Present}, []byte(`{}`), pgxpoolgo.AnyArg())
+
+	var payload *string
+	assert.Nil(t, argumentInsertEvent(ctx, mockPool, "e1", int64(7), payload))
+	assert.Nil(t, argumentInsertEvent(ctx, mockPool, "e2", uint32(8), []byte(`{}`)))
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestArgument_Mismatch(t *testing.T) {
+	ctx := context.Background()
+	mockPool := &pgxpoolgo.MockPool{}
+
+	mockPool.ExpectExec(`INSERT INTO events (id, user_id, payload, created_at) VALUES ($1, $2, $3, $4)`).
+		WithArgs(pgxpoolgo.UUIDv4(), int64(7), pgxpoolgo.JSONEq(`{"a": "x"}`), pgxpoolgo.TimeWithin(time.Second))
+
+	err := argumentInsertEvent(ctx, mockPool, "not-a-uuid", 7, `{"a": "y"}`)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "argument 0 expected [UUIDv4()] does not match actual [string - not-a-uuid]")
+	assert.Contains(t, err.Error(), `argument 2 expected [JSONEq({"a": "x"})] does not match actual [string - {"a": "y"}]`)
+	assert.NotContains(t, err.Error(), "argument 1")
+	assert.NotContains(t, err.Error(), "argument 3")
+}
+
+func TestArgument_Undefined(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockPool.ExpectExec(`INSERT INTO events (id, user_id, payload, created_at) VALUES ($1, $2, $3, $4)`).
+		WithArgs("e1", pgtype.Int8{}, nil, pgxpoolgo.AnyArg())
+
+	mockPool.ExpectExec(`INSERT INTO events (id, user_id, payload, created_at) VALUES ($1, $2, $3, $4)`).
+		WithArgs("e2", nil, nil, pgxpoolgo.AnyArg())
+
+	var userID *pgtype.Int8
+	assert.Nil(t, argumentInsertEvent(ctx, mockPool, "e1", &pgtype.Int8{}, nil))
+	assert.Nil(t, argumentInsertEvent(ctx, mockPool, "e2", userID, nil))
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+
+	for _, c := range []struct {
+		expected interface{}
+		actual   interface{}
+	}{
+		{pgtype.Int8{}, 0},
+		{0, pgtype.Int8{}},
+		{pgtype.Text{}, 0},
+		{pgtype.Int8{}, pgtype.Text{}},
+		{pgtype.Int8{}, pgtype.Int8{Int: 0, Status: pgtype.Present}},
+	} {
+		unexpectedPool := &pgxpoolgo.MockPool{}
+		unexpectedPool.ExpectExec(`INSERT INTO events (id, user_id, payload, created_at) VALUES ($1, $2, $3, $4)`).
+			WithArgs("e1", c.expected, nil, pgxpoolgo.AnyArg())
+
+		err := argumentInsertEvent(ctx, unexpectedPool, "e1", c.actual, nil)
+		assert.NotNil(t, err, "%T matched %T", c.expected, c.actual)
+		if err != nil {
+			assert.Contains(t, err.Error(), "argument 1 expected")
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package pgxpoolgo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"reflect"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+type MockBatchResults struct {
+	results []*mockBatchResult
+}
+
+type mockBatchResult struct {
+	method     string
+	commandTag pgconn.CommandTag
+	rows       *MockRows
+	row        *MockRow
+	err        error
+}
+
+type queuedQuery struct {
+	sql  string
+	args []interface{}
+}
+
+type batchResults struct {
+	results *MockBatchResults
+	batch   *pgx.Batch
+	index   int
+	closed  bool
+	errs    []string
+	rows    []*rows
+	mu      sync.Mutex
+}
+
+// NewMockBatchResults mocks pgx.BatchResults.
+func NewMockBatchResults() *MockBatchResults {
+	return &MockBatchResults{}
+}
+
+// AddExec scripts the command tag read by BatchResults.Exec for the next queued query.
+func (mbr *MockBatchResults) AddExec(tag pgconn.CommandTag) *MockBatchResults {
+	mbr.results = append(mbr.results, &mockBatchResult{method: "Exec", commandTag: tag})
+	return mbr
+}
+
+// AddQuery scripts the rows read by BatchResults.Query or BatchResults.QueryFunc for the next queued query.
+func (mbr *MockBatchResults) AddQuery(rows *MockRows) *MockBatchResults {
+	mbr.results = append(mbr.results, &mockBatchResult{method: "Query", rows: rows})
+	return mbr
+}
+
+// AddQueryRow scripts the row read by BatchResults.QueryRow for the next queued query.
+func (mbr *MockBatchResults) AddQueryRow(row *MockRow) *MockBatchResults {
+	mbr.results = append(mbr.results, &mockBatchResult{method: "QueryRow", row: row})
+	return mbr
+}
+
+// AddError scripts the error read by any method of BatchResults for the next queued query.
+func (mbr *MockBatchResults) AddError(err error) *MockBatchResults {
+	mbr.results = append(mbr.results, &mockBatchResult{err: err})
+	return mbr
+}
+
+func (mbr *MockBatchResults) Compose() pgx.BatchResults {
+	return &batchResults{results: mbr}
+}
+
+// bind sets the batch the results were returned for by SendBatch, so the reads can be verified against its queued
+// queries.
+func (br *batchResults) bind(b *pgx.Batch) {
+	br.batch = b
+}
+
+func (br *batchResults) next(method string) (*mockBatchResult, error) {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	if br.closed {
+		return nil, errors.New("batch already closed")
+	}
+	if br.batch != nil && br.index >= br.batch.Len() {
+		br.fail("batch result %d was read with %s, but only %d queries were queued", br.index, method, br.batch.Len())
+		return nil, errors.New("no result")
+	}
+	if br.index >= len(br.results.results) {
+		br.fail("batch result %d was read with %s, but only %d results were scripted", br.index, method, len(br.results.results))
+		return nil, errors.New("no result")
+	}
+	result := br.results.results[br.index]
+	br.index++
+	if result.err != nil {
+		return nil, result.err
+	}
+	if result.method != method && !(result.method == "Query" && method == "QueryFunc") {
+		err := fmt.Errorf("batch result %d was scripted for %s, but read with %s", br.index-1, result.method, method)
+		br.fail("%s", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+func (br *batchResults) fail(format string, args ...interface{}) {
+	br.errs = append(br.errs, fmt.Sprintf(format, args...))
+}
+
+func (br *batchResults) Exec() (pgconn.CommandTag, error) {
+	result, err := br.next("Exec")
+	if err != nil {
+		return nil, err
+	}
+	return result.commandTag, nil
+}
+
+func (br *batchResults) Query() (pgx.Rows, error) {
+	result, err := br.next("Query")
+	if err != nil {
+		return nil, err
+	}
+	r := ComposeRows(result.rows).(*rows)
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	if queued := queuedQueries(br.batch); len(queued) >= br.index {
+		r.bind(queued[br.index-1].sql)
+	}
+	br.rows = append(br.rows, r)
+	return r, nil
+}
+
+func (br *batchResults) QueryRow() pgx.Row {
+	result, err := br.next("QueryRow")
+	if err != nil {
+		return &errRow{err: err}
+	}
+	if result.row == nil {
+		return &errRow{err: errors.New("batch result was scripted for QueryRow without a row")}
+	}
+	return result.row.Compose()
+}
+
+func (br *batchResults) QueryFunc(scans []interface{}, f func(pgx.QueryFuncRow) error) (pgconn.CommandTag, error) {
+	result, err := br.next("QueryFunc")
+	if err != nil {
+		return nil, err
+	}
+	return queryFunc(result.rows.Compose(), scans, f)
+}
+
+func (br *batchResults) Close() error {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	if !br.closed {
+		br.closed = true
+		if br.batch != nil && br.index < br.batch.Len() {
+			br.fail("batch results were closed after reading %d of %d queued queries", br.index, br.batch.Len())
+		}
+		if br.batch != nil && len(br.results.results) != br.batch.Len() {
+			br.fail("%d batch results were scripted for %d queued queries", len(br.results.results), br.batch.Len())
+		}
+	}
+	return br.check(br.errs)
+}
+
+// verify returns the mismatches between the reads and the queued queries, whether Close was called, and whether
+// the rows read with Query were closed.
+func (br *batchResults) verify() error {
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	errs := append([]string(nil), br.errs...)
+	if !br.closed {
+		errs = append(errs, "batch results were not closed")
+	}
+	for _, r := range br.rows {
+		if err := r.verify(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	return br.check(errs)
+}
+
+func (br *batchResults) check(errs []string) error {
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// queryFunc runs f for every row of rows the way pgx.Conn.QueryFunc does.
+func queryFunc(rows pgx.Rows, scans []interface{}, f func(pgx.QueryFuncRow) error) (pgconn.CommandTag, error) {
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := rows.Scan(scans...); err != nil {
+			return nil, err
+		}
+		if err := f(rows); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rows.CommandTag(), nil
+}
+
+// queuedQueries returns the queries queued in b. pgx.Batch keeps them unexported, so they are read with reflect.
+func queuedQueries(b *pgx.Batch) []queuedQuery {
+	if b == nil {
+		return nil
+	}
+	items := reflect.ValueOf(b).Elem().FieldByName("items")
+	queries := make([]queuedQuery, items.Len())
+	for i := range queries {
+		item := items.Index(i).Elem()
+		args := item.FieldByName("arguments")
+		queries[i].sql = item.FieldByName("query").String()
+		queries[i].args = *(*[]interface{})(unsafe.Pointer(args.UnsafeAddr()))
+	}
+	return queries
+}
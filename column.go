@@ -0,0 +1,168 @@
+package pgxpoolgo
+
+import (
+	"fmt"
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgtype"
+	"strings"
+)
+
+// MockColumn describes a column of MockRows and MockRow, the way the server describes it in FieldDescriptions.
+type MockColumn struct {
+	def      pgproto3.FieldDescription
+	nullable bool
+}
+
+// typeAliases maps the SQL names of the types to their pgtype names.
+var typeAliases = map[string]string{
+	"bigint":                      "int8",
+	"bigserial":                   "int8",
+	"boolean":                     "bool",
+	"character":                   "bpchar",
+	"character varying":           "varchar",
+	"decimal":                     "numeric",
+	"double precision":            "float8",
+	"int":                         "int4",
+	"integer":                     "int4",
+	"real":                        "float4",
+	"serial":                      "int4",
+	"smallint":                    "int2",
+	"smallserial":                 "int2",
+	"timestamp with time zone":    "timestamptz",
+	"timestamp without time zone": "timestamp",
+	"time without time zone":      "time",
+}
+
+// typeSizes maps the OIDs of the fixed size types to their size, reported as DataTypeSize.
+var typeSizes = map[uint32]int16{
+	pgtype.BoolOID:        1,
+	pgtype.Int2OID:        2,
+	pgtype.Int4OID:        4,
+	pgtype.Int8OID:        8,
+	pgtype.OIDOID:         4,
+	pgtype.Float4OID:      4,
+	pgtype.Float8OID:      8,
+	pgtype.DateOID:        4,
+	pgtype.TimeOID:        8,
+	pgtype.TimestampOID:   8,
+	pgtype.TimestamptzOID: 8,
+	pgtype.IntervalOID:    16,
+	pgtype.UUIDOID:        16,
+}
+
+// NewMockColumn describes a nullable column named name. Its type is inferred from the first value added to the rows
+// when it is not set with OfType or WithOID.
+func NewMockColumn(name string) *MockColumn {
+	return &MockColumn{
+		def: pgproto3.FieldDescription{
+			Name:         []byte(name),
+			DataTypeSize: -1,
+			TypeModifier: -1,
+		},
+		nullable: true,
+	}
+}
+
+// OfType sets the type of the column by its PostgreSQL name, like "bigint", "text" or "timestamptz". Array types
+// are named with a "[]" suffix. It panics when the type is unknown.
+func (mc *MockColumn) OfType(typeName string) *MockColumn {
+	dt, ok := dataTypeForName(pgtype.NewConnInfo(), typeName)
+	if !ok {
+		panic(fmt.Sprintf("unknown type %s of column %s", typeName, mc.def.Name))
+	}
+	return mc.WithOID(dt.OID)
+}
+
+// dataTypeForName returns the data type of the PostgreSQL type named typeName, which can be an alias like "bigint",
+// or an array type with a "[]" suffix.
+func dataTypeForName(ci *pgtype.ConnInfo, typeName string) (*pgtype.DataType, bool) {
+	name := strings.ToLower(strings.TrimSpace(typeName))
+	array := strings.HasSuffix(name, "[]")
+	name = strings.TrimSuffix(name, "[]")
+	if alias, ok := typeAliases[name]; ok {
+		name = alias
+	}
+	if array {
+		name = "_" + name
+	}
+	return ci.DataTypeForName(name)
+}
+
+// WithOID sets the type of the column by its OID.
+func (mc *MockColumn) WithOID(oid uint32) *MockColumn {
+	mc.def.DataTypeOID = oid
+	mc.def.DataTypeSize = -1
+	if size, ok := typeSizes[oid]; ok {
+		mc.def.DataTypeSize = size
+	}
+	mc.def.Format = pgtype.NewConnInfo().ResultFormatCodeForOID(oid)
+	return mc
+}
+
+// Nullable sets whether the column can hold NULL. Adding a nil value to a column which is not nullable panics.
+func (mc *MockColumn) Nullable(nullable bool) *MockColumn {
+	mc.nullable = nullable
+	return mc
+}
+
+// FromTable sets the table OID and the attribute number of the column.
+func (mc *MockColumn) FromTable(tableOID uint32, attributeNumber uint16) *MockColumn {
+	mc.def.TableOID = tableOID
+	mc.def.TableAttributeNumber = attributeNumber
+	return mc
+}
+
+// WithFormat sets the format code of the column, pgtype.TextFormatCode or pgtype.BinaryFormatCode. Setting the type
+// of the column sets the format pgx reads the type in, so WithFormat is used after OfType or WithOID.
+func (mc *MockColumn) WithFormat(formatCode int16) *MockColumn {
+	mc.def.Format = formatCode
+	return mc
+}
+
+// NewMockRowsWithColumns mocks pgx.Rows with columns described by MockColumn.
+func NewMockRowsWithColumns(columns ...*MockColumn) *MockRows {
+	mr := NewMockRows(nil)
+	mr.defs, mr.nullable = columnDefs(columns)
+	return mr
+}
+
+// NewMockRowWithColumns mocks pgx.Row with columns described by MockColumn.
+func NewMockRowWithColumns(columns ...*MockColumn) *MockRow {
+	mr := NewMockRow(nil)
+	mr.defs, mr.nullable = columnDefs(columns)
+	return mr
+}
+
+func columnDefs(columns []*MockColumn) ([]pgproto3.FieldDescription, []bool) {
+	defs := make([]pgproto3.FieldDescription, len(columns))
+	nullable := make([]bool, len(columns))
+	for i, column := range columns {
+		defs[i] = column.def
+		nullable[i] = column.nullable
+	}
+	return defs, nullable
+}
+
+// inferColumns sets the type of the columns without one from the values of a row added to them. It panics when a
+// value is nil in a column which is not nullable.
+func inferColumns(defs []pgproto3.FieldDescription, nullable []bool, values []interface{}) {
+	var ci *pgtype.ConnInfo
+	for i, value := range values {
+		if value == nil {
+			if nullable != nil && !nullable[i] {
+				panic(fmt.Sprintf("expected a value for column %s, which is not nullable", defs[i].Name))
+			}
+			continue
+		}
+		if defs[i].DataTypeOID != 0 {
+			continue
+		}
+		if ci == nil {
+			ci = pgtype.NewConnInfo()
+		}
+		if dt, ok := ci.DataTypeForValue(value); ok {
+			column := &MockColumn{def: defs[i]}
+			defs[i] = column.WithOID(dt.OID).def
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package pgxpoolgo_test
+
+import (
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMockColumn_OK(t *testing.T) {
+	mockRows := pgxpoolgo.NewMockRowsWithColumns(
+		pgxpoolgo.NewMockColumn("id").OfType("bigint").Nullable(false).FromTable(16390, 1),
+		pgxpoolgo.NewMockColumn("tags").OfType("text[]"),
+		pgxpoolgo.NewMockColumn("email").WithOID(pgtype.VarcharOID).WithFormat(pgtype.TextFormatCode),
+	).AddRow(int64(1), []string{"admin"}, "johndoe@email.com").Compose()
+
+	defs := mockRows.FieldDescriptions()
+	assert.Equal(t, "id", string(defs[0].Name))
+	assert.Equal(t, uint32(pgtype.Int8OID), defs[0].DataTypeOID)
+	assert.Equal(t, int16(8), defs[0].DataTypeSize)
+	assert.Equal(t, int16(pgtype.BinaryFormatCode), defs[0].Format)
+	assert.Equal(t, uint32(16390), defs[0].TableOID)
+	assert.Equal(t, uint16(1), defs[0].TableAttributeNumber)
+	assert.Equal(t, uint32(pgtype.TextArrayOID), defs[1].DataTypeOID)
+	assert.Equal(t, int16(-1), defs[1].DataTypeSize)
+	assert.Equal(t, uint32(pgtype.VarcharOID), defs[2].DataTypeOID)
+	assert.Equal(t, int16(pgtype.TextFormatCode), defs[2].Format)
+}
+
+func TestMockColumn_Inferred(t *testing.T) {
+	mockRow := pgxpoolgo.NewMockRow([]string{"id", "name", "created_at", "note"}).
+		AddRow(1, "johndoe", time.Now(), nil)
+
+	var id int
+	var name string
+	var createdAt time.Time
+	var note *string
+	assert.Nil(t, mockRow.Compose().Scan(&id, &name, &createdAt, &note))
+
+	mockRows := pgxpoolgo.NewMockRows([]string{"id", "name", "created_at", "note"}).
+		AddRow(1, "johndoe", time.Now(), nil).Compose()
+
+	defs := mockRows.FieldDescriptions()
+	assert.Equal(t, uint32(pgtype.Int8OID), defs[0].DataTypeOID)
+	assert.Equal(t, uint32(pgtype.TextOID), defs[1].DataTypeOID)
+	assert.Equal(t, uint32(pgtype.TimestamptzOID), defs[2].DataTypeOID)
+	assert.Equal(t, uint32(0), defs[3].DataTypeOID)
+}
+
+func TestMockColumn_NotNullable(t *testing.T) {
+	assert.Panics(t, func() {
+		pgxpoolgo.NewMockRowsWithColumns(pgxpoolgo.NewMockColumn("id").Nullable(false)).AddRow(nil)
+	})
+	assert.Panics(t, func() {
+		pgxpoolgo.NewMockColumn("id").OfType("nosuchtype")
+	})
+}
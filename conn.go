@@ -0,0 +1,69 @@
+package pgxpoolgo
+
+import (
+	"context"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Conn is a connection acquired from a pool, implemented by pgxpool.Conn and MockConn.
+type Conn interface {
+	Release()
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	QueryFunc(ctx context.Context, sql string, args []interface{}, scans []interface{}, f func(pgx.QueryFuncRow) error) (pgconn.CommandTag, error)
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+	BeginFunc(ctx context.Context, f func(pgx.Tx) error) error
+	BeginTxFunc(ctx context.Context, txOptions pgx.TxOptions, f func(pgx.Tx) error) error
+	Ping(ctx context.Context) error
+	Conn() *pgx.Conn
+}
+
+// ConnPool is a Pool which also acquires connections as Conn, so the code pinning a connection can be tested with
+// MockPool and MockConn.
+type ConnPool interface {
+	Pool
+	AcquireConn(ctx context.Context) (Conn, error)
+	AcquireConnFunc(ctx context.Context, f func(Conn) error) error
+	AcquireAllIdleConns(ctx context.Context) []Conn
+}
+
+type connPool struct {
+	*pgxpool.Pool
+}
+
+// NewConnPool wraps pgxpool.Pool into ConnPool.
+func NewConnPool(pool *pgxpool.Pool) ConnPool {
+	return &connPool{Pool: pool}
+}
+
+// AcquireConn runs pgxpool.Pool.Acquire.
+func (p *connPool) AcquireConn(ctx context.Context) (Conn, error) {
+	conn, err := p.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// AcquireConnFunc runs pgxpool.Pool.AcquireFunc.
+func (p *connPool) AcquireConnFunc(ctx context.Context, f func(Conn) error) error {
+	return p.Pool.AcquireFunc(ctx, func(conn *pgxpool.Conn) error {
+		return f(conn)
+	})
+}
+
+// AcquireAllIdleConns runs pgxpool.Pool.AcquireAllIdle.
+func (p *connPool) AcquireAllIdleConns(ctx context.Context) []Conn {
+	idle := p.Pool.AcquireAllIdle(ctx)
+	conns := make([]Conn, len(idle))
+	for i, conn := range idle {
+		conns[i] = conn
+	}
+	return conns
+}
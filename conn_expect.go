@@ -0,0 +1,121 @@
+package pgxpoolgo
+
+import (
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/mock"
+)
+
+func (_m *MockConn) state() *mockState {
+	return stateOf(&_m.Mock, "MockConn")
+}
+
+// Called answers a mocked call from the expectations registered with the Expect methods, and falls back to the
+// expectations registered with On. Expectations can be registered with the Expect methods while other goroutines call
+// the mock, but On must be called before, testify giving no safe way to read them concurrently.
+func (_m *MockConn) Called(arguments ...interface{}) mock.Arguments {
+	method := calledMethod()
+	leave := _m.state().enter()
+	defer leave()
+	ret, ok := _m.state().called(method, arguments)
+	if !ok {
+		ret = _m.Mock.MethodCalled(method, arguments...)
+	}
+	_m.state().returned(method, arguments, ret)
+	return ret
+}
+
+// AssertExpectations asserts that everything specified with On and Return, and every expectation registered with
+// the Expect methods, was in fact called as expected.
+func (_m *MockConn) AssertExpectations(t mock.TestingT) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	ok := _m.Mock.AssertExpectations(t)
+	return _m.state().assertExpectations(t) && ok
+}
+
+// MatchExpectationsInOrder sets whether the calls of the mock, and of the mocks it hands out unless they are set
+// otherwise, must match the expectations in the order they were registered. The mock which handed it out, and the
+// other mocks that one handed out, are not affected. They are matched in order by default.
+func (_m *MockConn) MatchExpectationsInOrder(b bool) {
+	_m.state().matchInOrder(b)
+}
+
+// ExpectationsWereMet checks whether all the expectations registered with the Expect methods, on the mock and on
+// every mock it handed out, were met and no unexpected call was made.
+func (_m *MockConn) ExpectationsWereMet() error {
+	return _m.state().expectationsWereMet()
+}
+
+// UseQueryMatcher sets the QueryMatcher of the Expect methods. QueryMatcherNormalized is used by default.
+func (_m *MockConn) UseQueryMatcher(qm QueryMatcher) {
+	_m.state().useQueryMatcher(qm)
+}
+
+// ExpectExec expects Conn.Exec to be called with the expected SQL.
+func (_m *MockConn) ExpectExec(expectedSQL string) *ExpectedExec {
+	e := &ExpectedExec{}
+	e.expectSQL = expectedSQL
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectQuery expects Conn.Query to be called with the expected SQL.
+func (_m *MockConn) ExpectQuery(expectedSQL string) *ExpectedQuery {
+	e := &ExpectedQuery{}
+	e.expectSQL = expectedSQL
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectQueryRow expects Conn.QueryRow to be called with the expected SQL.
+func (_m *MockConn) ExpectQueryRow(expectedSQL string) *ExpectedQueryRow {
+	e := &ExpectedQueryRow{}
+	e.expectSQL = expectedSQL
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectQueryFunc expects Conn.QueryFunc to be called with the expected SQL.
+func (_m *MockConn) ExpectQueryFunc(expectedSQL string) *ExpectedQueryFunc {
+	e := &ExpectedQueryFunc{}
+	e.expectSQL = expectedSQL
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectSendBatch expects Conn.SendBatch to be called.
+func (_m *MockConn) ExpectSendBatch() *ExpectedSendBatch {
+	e := &ExpectedSendBatch{}
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectCopyFrom expects Conn.CopyFrom to be called with the expected table name and column names.
+func (_m *MockConn) ExpectCopyFrom(tableName pgx.Identifier, columnNames []string) *ExpectedCopyFrom {
+	e := &ExpectedCopyFrom{tableName: tableName, columns: columnNames}
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectBegin expects Conn.Begin, Conn.BeginTx, Conn.BeginFunc or Conn.BeginTxFunc to be called. BeginFunc and
+// BeginTxFunc run their function with the MockTx, which is then committed, or rolled back when the function fails.
+func (_m *MockConn) ExpectBegin() *ExpectedBegin {
+	e := &ExpectedBegin{}
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectBeginTx expects Conn.BeginTx or Conn.BeginTxFunc to be called with the expected transaction options.
+func (_m *MockConn) ExpectBeginTx(txOptions pgx.TxOptions) *ExpectedBegin {
+	e := &ExpectedBegin{opts: &txOptions}
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectRelease expects Conn.Release to be called.
+func (_m *MockConn) ExpectRelease() *ExpectedRelease {
+	e := &ExpectedRelease{}
+	_m.state().expect(e)
+	return e
+}
@@ -0,0 +1,1122 @@
+package pgxpoolgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/mock"
+)
+
+/*
+The codes below is based on `pgxmock` from `github.com/pashagolub/pgxmock`
+*/
+
+// an expectation interface
+type expectation interface {
+	fmt.Stringer
+	fulfilled() bool
+	trigger()
+	progress() (calls, times int)
+	own(s *mockState)
+	owner() *mockState
+	expects(method string) bool
+	match(call *mockCall, qm QueryMatcher) error
+	respond(call *mockCall) mock.Arguments
+	wait(ctx context.Context) error
+}
+
+// common expectation struct
+// satisfies the expectation interface
+type commonExpectation struct {
+	state *mockState
+	calls int
+	times int
+	err   error
+	delay time.Duration
+}
+
+func (e *commonExpectation) fulfilled() bool {
+	_, times := e.progress()
+	return e.calls >= times
+}
+
+func (e *commonExpectation) trigger() {
+	e.calls++
+}
+
+// progress returns how many times the expectation was triggered, out of the times it expects.
+func (e *commonExpectation) progress() (calls, times int) {
+	if e.times < 1 {
+		return e.calls, 1
+	}
+	return e.calls, e.times
+}
+
+// expectTimes sets the times of an expectation, which builders require to be positive.
+func (e *commonExpectation) expectTimes(n int) {
+	if n < 1 {
+		panic("Times: n must be positive")
+	}
+	e.times = n
+}
+
+func (e *commonExpectation) own(s *mockState) {
+	e.state = s
+}
+
+func (e *commonExpectation) owner() *mockState {
+	return e.state
+}
+
+// wait blocks for the delay of the expectation, and fails when ctx is done before, or already done without delay, the
+// way pgconn fails.
+func (e *commonExpectation) wait(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return contextDoneError(ctx)
+	}
+	if e.delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(e.delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return timeoutError(ctx)
+	}
+}
+
+// query based expectation
+// adds a query matching logic
+type queryBasedExpectation struct {
+	commonExpectation
+	expectSQL string
+	args      []interface{}
+}
+
+func (e *queryBasedExpectation) expectedSQL() string {
+	return e.expectSQL
+}
+
+func (e *queryBasedExpectation) match(call *mockCall, qm QueryMatcher) error {
+	if err := qm.Match(e.expectSQL, call.sql()); err != nil {
+		return err
+	}
+	return argsMatches(e.args, call.queryArgs())
+}
+
+func (e *queryBasedExpectation) string(name, methods string) string {
+	msg := name + " => expecting " + methods + " which:"
+	msg += "\n  - matches sql: '" + e.expectSQL + "'"
+	if len(e.args) == 0 {
+		msg += "\n  - is without arguments"
+	} else {
+		msg += "\n  - is with arguments:"
+		for i, arg := range e.args {
+			msg += fmt.Sprintf("\n    %d - %+v", i, arg)
+		}
+	}
+	if e.err != nil {
+		msg += fmt.Sprintf("\n  - should return error: %s", e.err)
+	}
+	return msg
+}
+
+// ExpectedExec is used to manage Pool.Exec and Tx.Exec expectations. Returned by the ExpectExec methods.
+type ExpectedExec struct {
+	queryBasedExpectation
+	result pgconn.CommandTag
+}
+
+// WithArgs will match given expected args to actual exec arguments.
+func (e *ExpectedExec) WithArgs(args ...interface{}) *ExpectedExec {
+	e.args = args
+	return e
+}
+
+// WillReturnResult arranges for an expected Exec to return a particular command tag.
+func (e *ExpectedExec) WillReturnResult(result pgconn.CommandTag) *ExpectedExec {
+	e.result = result
+	return e
+}
+
+// WillReturnError allows to set an error for expected Exec.
+func (e *ExpectedExec) WillReturnError(err error) *ExpectedExec {
+	e.err = err
+	return e
+}
+
+// Times arranges for the expectation to be met by n calls instead of one, which get the same answer.
+func (e *ExpectedExec) Times(n int) *ExpectedExec {
+	e.expectTimes(n)
+	return e
+}
+
+// WillDelayFor arranges for an expected Exec to answer after d, or to fail like pgconn when its context is
+// done before.
+func (e *ExpectedExec) WillDelayFor(d time.Duration) *ExpectedExec {
+	e.delay = d
+	return e
+}
+
+// String returns string representation.
+func (e *ExpectedExec) String() string {
+	msg := e.string("ExpectedExec", "Exec")
+	if e.result != nil {
+		msg += fmt.Sprintf("\n  - should return result: %s", e.result)
+	}
+	return msg
+}
+
+func (e *ExpectedExec) expects(method string) bool {
+	return method == "Exec"
+}
+
+func (e *ExpectedExec) respond(_ *mockCall) mock.Arguments {
+	if e.err != nil {
+		return mock.Arguments{nil, e.err}
+	}
+	return mock.Arguments{e.result, nil}
+}
+
+// ExpectedQuery is used to manage Pool.Query and Tx.Query expectations. Returned by the ExpectQuery methods.
+type ExpectedQuery struct {
+	queryBasedExpectation
+	rows []*MockRows
+}
+
+// WithArgs will match given expected args to actual query arguments.
+func (e *ExpectedQuery) WithArgs(args ...interface{}) *ExpectedQuery {
+	e.args = args
+	return e
+}
+
+// WillReturnRows specifies the rows that will be returned by the triggered query. Several rows are returned as
+// result sets read one after another.
+func (e *ExpectedQuery) WillReturnRows(rows ...*MockRows) *ExpectedQuery {
+	e.rows = rows
+	return e
+}
+
+// WillReturnError allows to set an error for expected Query.
+func (e *ExpectedQuery) WillReturnError(err error) *ExpectedQuery {
+	e.err = err
+	return e
+}
+
+// Times arranges for the expectation to be met by n calls instead of one, which get the same answer.
+func (e *ExpectedQuery) Times(n int) *ExpectedQuery {
+	e.expectTimes(n)
+	return e
+}
+
+// WillDelayFor arranges for an expected Query to answer after d, or to fail like pgconn when its context is
+// done before.
+func (e *ExpectedQuery) WillDelayFor(d time.Duration) *ExpectedQuery {
+	e.delay = d
+	return e
+}
+
+// String returns string representation.
+func (e *ExpectedQuery) String() string {
+	return e.string("ExpectedQuery", "Query")
+}
+
+func (e *ExpectedQuery) expects(method string) bool {
+	return method == "Query"
+}
+
+func (e *ExpectedQuery) respond(call *mockCall) mock.Arguments {
+	if e.err != nil {
+		return mock.Arguments{nil, e.err}
+	}
+	if len(e.rows) == 0 {
+		return mock.Arguments{nil, fmt.Errorf("Query '%s' must return a pgx.Rows, but it was not set for %s", call.sql(), e)}
+	}
+	return mock.Arguments{ComposeRows(e.rows...), nil}
+}
+
+// ExpectedQueryRow is used to manage Pool.QueryRow and Tx.QueryRow expectations. Returned by the ExpectQueryRow
+// methods.
+type ExpectedQueryRow struct {
+	queryBasedExpectation
+	row *MockRow
+}
+
+// WithArgs will match given expected args to actual query arguments.
+func (e *ExpectedQueryRow) WithArgs(args ...interface{}) *ExpectedQueryRow {
+	e.args = args
+	return e
+}
+
+// WillReturnRow specifies the row that will be returned by the triggered query.
+func (e *ExpectedQueryRow) WillReturnRow(row *MockRow) *ExpectedQueryRow {
+	e.row = row
+	return e
+}
+
+// WillReturnError allows to set an error returned by Scan of the row of the expected QueryRow.
+func (e *ExpectedQueryRow) WillReturnError(err error) *ExpectedQueryRow {
+	e.err = err
+	return e
+}
+
+// Times arranges for the expectation to be met by n calls instead of one, which get the same answer.
+func (e *ExpectedQueryRow) Times(n int) *ExpectedQueryRow {
+	e.expectTimes(n)
+	return e
+}
+
+// WillDelayFor arranges for an expected QueryRow to answer after d, or to fail like pgconn when its context is
+// done before.
+func (e *ExpectedQueryRow) WillDelayFor(d time.Duration) *ExpectedQueryRow {
+	e.delay = d
+	return e
+}
+
+// String returns string representation.
+func (e *ExpectedQueryRow) String() string {
+	return e.string("ExpectedQueryRow", "QueryRow")
+}
+
+func (e *ExpectedQueryRow) expects(method string) bool {
+	return method == "QueryRow"
+}
+
+func (e *ExpectedQueryRow) respond(call *mockCall) mock.Arguments {
+	if e.err != nil {
+		return mock.Arguments{&errRow{err: e.err}}
+	}
+	if e.row == nil {
+		return mock.Arguments{&errRow{err: fmt.Errorf("QueryRow '%s' must return a pgx.Row, but it was not set for %s", call.sql(), e)}}
+	}
+	return mock.Arguments{e.row.Compose()}
+}
+
+// ExpectedQueryFunc is used to manage Pool.QueryFunc and Tx.QueryFunc expectations. Returned by the ExpectQueryFunc
+// methods.
+type ExpectedQueryFunc struct {
+	queryBasedExpectation
+	result pgconn.CommandTag
+	rows   []*MockRows
+}
+
+// WithArgs will match given expected args to actual query arguments.
+func (e *ExpectedQueryFunc) WithArgs(args ...interface{}) *ExpectedQueryFunc {
+	e.args = args
+	return e
+}
+
+// WillReturnResult arranges for an expected QueryFunc to return a particular command tag.
+func (e *ExpectedQueryFunc) WillReturnResult(result pgconn.CommandTag) *ExpectedQueryFunc {
+	e.result = result
+	return e
+}
+
+// WillReturnRows specifies the rows read by the triggered QueryFunc. Every row is scanned into the scans of QueryFunc
+// before its function is called with the row, and QueryFunc returns the command tag of the rows. Several rows are
+// read as result sets one after another.
+func (e *ExpectedQueryFunc) WillReturnRows(rows ...*MockRows) *ExpectedQueryFunc {
+	e.rows = rows
+	return e
+}
+
+// WillReturnError allows to set an error for expected QueryFunc.
+func (e *ExpectedQueryFunc) WillReturnError(err error) *ExpectedQueryFunc {
+	e.err = err
+	return e
+}
+
+// Times arranges for the expectation to be met by n calls instead of one, which get the same answer.
+func (e *ExpectedQueryFunc) Times(n int) *ExpectedQueryFunc {
+	e.expectTimes(n)
+	return e
+}
+
+// WillDelayFor arranges for an expected QueryFunc to answer after d, or to fail like pgconn when its context is
+// done before.
+func (e *ExpectedQueryFunc) WillDelayFor(d time.Duration) *ExpectedQueryFunc {
+	e.delay = d
+	return e
+}
+
+// String returns string representation.
+func (e *ExpectedQueryFunc) String() string {
+	return e.string("ExpectedQueryFunc", "QueryFunc")
+}
+
+func (e *ExpectedQueryFunc) expects(method string) bool {
+	return method == "QueryFunc"
+}
+
+func (e *ExpectedQueryFunc) respond(call *mockCall) mock.Arguments {
+	if e.err != nil {
+		return mock.Arguments{nil, e.err}
+	}
+	if len(e.rows) == 0 {
+		return mock.Arguments{e.result, nil}
+	}
+	scans, _ := call.args[3].([]interface{})
+	f, _ := call.args[4].(func(pgx.QueryFuncRow) error)
+	tag, err := queryFunc(ComposeRows(e.rows...), scans, f)
+	return mock.Arguments{tag, err}
+}
+
+// ExpectedSendBatch is used to manage Pool.SendBatch and Tx.SendBatch expectations. Returned by the
+// ExpectSendBatch methods.
+type ExpectedSendBatch struct {
+	commonExpectation
+	queued  []queuedQuery
+	results pgx.BatchResults
+}
+
+// WithQueued expects the next query queued in the batch to match the expected SQL and to have exactly args. When it
+// is used, the batch must hold exactly the queries expected with WithQueued.
+func (e *ExpectedSendBatch) WithQueued(expectedSQL string, args ...interface{}) *ExpectedSendBatch {
+	if args == nil {
+		args = []interface{}{}
+	}
+	e.queued = append(e.queued, queuedQuery{sql: expectedSQL, args: args})
+	return e
+}
+
+// WithBatch expects the batch to hold exactly the queries queued in b.
+func (e *ExpectedSendBatch) WithBatch(b *pgx.Batch) *ExpectedSendBatch {
+	for _, q := range queuedQueries(b) {
+		e.WithQueued(q.sql, q.args...)
+	}
+	return e
+}
+
+// WillReturnBatchResults specifies the batch results that will be returned by the triggered SendBatch.
+func (e *ExpectedSendBatch) WillReturnBatchResults(results pgx.BatchResults) *ExpectedSendBatch {
+	e.results = results
+	return e
+}
+
+// WillReturnError allows to set an error returned by every method of the batch results of the expected SendBatch.
+func (e *ExpectedSendBatch) WillReturnError(err error) *ExpectedSendBatch {
+	e.err = err
+	return e
+}
+
+// Times arranges for the expectation to be met by n calls instead of one, which get the same answer.
+func (e *ExpectedSendBatch) Times(n int) *ExpectedSendBatch {
+	e.expectTimes(n)
+	return e
+}
+
+// WillDelayFor arranges for an expected SendBatch to answer after d, or to fail like pgconn when its context is
+// done before.
+func (e *ExpectedSendBatch) WillDelayFor(d time.Duration) *ExpectedSendBatch {
+	e.delay = d
+	return e
+}
+
+// String returns string representation.
+func (e *ExpectedSendBatch) String() string {
+	msg := "ExpectedSendBatch => expecting SendBatch"
+	if len(e.queued) > 0 {
+		msg += " which:\n  - has queued queries:"
+		for i, q := range e.queued {
+			msg += fmt.Sprintf("\n    %d - '%s' with args %+v", i, q.sql, q.args)
+		}
+	}
+	if e.err != nil {
+		msg += fmt.Sprintf("\n  - should return error: %s", e.err)
+	}
+	return msg
+}
+
+func (e *ExpectedSendBatch) expects(method string) bool {
+	return method == "SendBatch"
+}
+
+func (e *ExpectedSendBatch) match(call *mockCall, qm QueryMatcher) error {
+	if e.queued == nil {
+		return nil
+	}
+	b, _ := call.args[1].(*pgx.Batch)
+	queued := queuedQueries(b)
+	if len(queued) != len(e.queued) {
+		return fmt.Errorf("expected %d queued queries, but got %d", len(e.queued), len(queued))
+	}
+	for i, q := range queued {
+		if err := qm.Match(e.queued[i].sql, q.sql); err != nil {
+			return fmt.Errorf("queued query %d differs: %s\n%s", i, err, queryDiff(e.queued[i].sql, q.sql))
+		}
+		if err := argsMatches(e.queued[i].args, q.args); err != nil {
+			return fmt.Errorf("queued query %d '%s' differs: %s", i, stripQuery(q.sql), err)
+		}
+	}
+	return nil
+}
+
+func (e *ExpectedSendBatch) respond(_ *mockCall) mock.Arguments {
+	if e.err != nil {
+		return mock.Arguments{&errBatchResults{err: e.err}}
+	}
+	if e.results == nil {
+		return mock.Arguments{&errBatchResults{err: fmt.Errorf("SendBatch must return a pgx.BatchResults, but it was not set for %s", e)}}
+	}
+	if br, ok := e.results.(*batchResults); ok {
+		return mock.Arguments{&batchResults{results: br.results}}
+	}
+	return mock.Arguments{e.results}
+}
+
+// ExpectedCopyFrom is used to manage Pool.CopyFrom and Tx.CopyFrom expectations. Returned by the ExpectCopyFrom
+// methods. The triggered CopyFrom drains the copy source and records the copied rows.
+type ExpectedCopyFrom struct {
+	commonExpectation
+	tableName    pgx.Identifier
+	columns      []string
+	rows         [][]interface{}
+	copied       [][]interface{}
+	mu           sync.Mutex
+	rowsAffected *int64
+	failAfter    int
+	failErr      error
+}
+
+// WithRows expects the copy source to yield exactly the rows. The values are matched like the args of WithArgs.
+func (e *ExpectedCopyFrom) WithRows(rows ...[]interface{}) *ExpectedCopyFrom {
+	e.rows = rows
+	return e
+}
+
+// WillReturnResult arranges for an expected CopyFrom to return a particular row count. The number of copied rows is
+// returned when it is not set.
+func (e *ExpectedCopyFrom) WillReturnResult(rowsAffected int64) *ExpectedCopyFrom {
+	e.rowsAffected = &rowsAffected
+	return e
+}
+
+// WillReturnError allows to set an error for expected CopyFrom, returned after the copy source was drained.
+func (e *ExpectedCopyFrom) WillReturnError(err error) *ExpectedCopyFrom {
+	e.err = err
+	return e
+}
+
+// WillFailAfter arranges for an expected CopyFrom to fail with err after copying n rows, without draining the rest
+// of the copy source.
+func (e *ExpectedCopyFrom) WillFailAfter(n int, err error) *ExpectedCopyFrom {
+	e.failAfter = n
+	e.failErr = err
+	return e
+}
+
+// CopiedRows returns the rows copied by the triggered CopyFrom, by the last call when it expects several.
+func (e *ExpectedCopyFrom) CopiedRows() [][]interface{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.copied
+}
+
+// Times arranges for the expectation to be met by n calls instead of one, which get the same answer.
+func (e *ExpectedCopyFrom) Times(n int) *ExpectedCopyFrom {
+	e.expectTimes(n)
+	return e
+}
+
+// WillDelayFor arranges for an expected CopyFrom to answer after d, or to fail like pgconn when its context is
+// done before.
+func (e *ExpectedCopyFrom) WillDelayFor(d time.Duration) *ExpectedCopyFrom {
+	e.delay = d
+	return e
+}
+
+// String returns string representation.
+func (e *ExpectedCopyFrom) String() string {
+	msg := "ExpectedCopyFrom => expecting CopyFrom which:"
+	msg += "\n  - matches table name: '" + e.tableName.Sanitize() + "'"
+	msg += fmt.Sprintf("\n  - matches column names: %v", e.columns)
+	if e.rows != nil {
+		msg += fmt.Sprintf("\n  - copies %d rows", len(e.rows))
+	}
+	if e.failErr != nil {
+		msg += fmt.Sprintf("\n  - should fail after %d rows with error: %s", e.failAfter, e.failErr)
+	}
+	if e.err != nil {
+		msg += fmt.Sprintf("\n  - should return error: %s", e.err)
+	}
+	return msg
+}
+
+func (e *ExpectedCopyFrom) expects(method string) bool {
+	return method == "CopyFrom"
+}
+
+func (e *ExpectedCopyFrom) match(call *mockCall, _ QueryMatcher) error {
+	tableName, _ := call.args[1].(pgx.Identifier)
+	if tableName.Sanitize() != e.tableName.Sanitize() {
+		return fmt.Errorf("table name '%s' does not match expected '%s'", tableName.Sanitize(), e.tableName.Sanitize())
+	}
+	columns, _ := call.args[2].([]string)
+	if !reflect.DeepEqual(columns, e.columns) {
+		return fmt.Errorf("column names %v do not match expected %v", columns, e.columns)
+	}
+	return nil
+}
+
+func (e *ExpectedCopyFrom) respond(call *mockCall) mock.Arguments {
+	copied, err := e.drain(call.args[3].(pgx.CopyFromSource))
+	e.mu.Lock()
+	e.copied = copied
+	e.mu.Unlock()
+	if err != nil {
+		return mock.Arguments{int64(0), err}
+	}
+	if e.err != nil {
+		return mock.Arguments{int64(0), e.err}
+	}
+	if e.rowsAffected != nil {
+		return mock.Arguments{*e.rowsAffected, nil}
+	}
+	return mock.Arguments{int64(len(copied)), nil}
+}
+
+// drain reads the copy source the way pgx.Conn.CopyFrom does and verifies the copied rows, which it returns. The
+// values of each row are copied, the source being free to reuse them once pgx encoded them.
+func (e *ExpectedCopyFrom) drain(src pgx.CopyFromSource) ([][]interface{}, error) {
+	var copied [][]interface{}
+	for src.Next() {
+		if e.failErr != nil && len(copied) == e.failAfter {
+			return copied, e.failErr
+		}
+		values, err := src.Values()
+		if err != nil {
+			return copied, err
+		}
+		if len(values) != len(e.columns) {
+			err := fmt.Errorf("expected %d values, got %d values", len(e.columns), len(values))
+			e.state.fail(fmt.Errorf("CopyFrom %s: %s", e.tableName.Sanitize(), err))
+			return copied, err
+		}
+		copied = append(copied, append([]interface{}(nil), values...))
+	}
+	if err := src.Err(); err != nil {
+		return copied, err
+	}
+	if e.failErr != nil && len(copied) == e.failAfter {
+		return copied, e.failErr
+	}
+	if e.rows == nil {
+		return copied, nil
+	}
+	if len(copied) != len(e.rows) {
+		err := fmt.Errorf("CopyFrom %s: expected %d copied rows, but got %d", e.tableName.Sanitize(), len(e.rows), len(copied))
+		e.state.fail(err)
+		return copied, err
+	}
+	for i, values := range copied {
+		if err := argsMatches(e.rows[i], values); err != nil {
+			err = fmt.Errorf("CopyFrom %s: copied row %d does not match: %s", e.tableName.Sanitize(), i, err)
+			e.state.fail(err)
+			return copied, err
+		}
+	}
+	return copied, nil
+}
+
+// ExpectedPrepare is used to manage Tx.Prepare expectations. Returned by MockTx.ExpectPrepare.
+type ExpectedPrepare struct {
+	queryBasedExpectation
+	name      string
+	paramOIDs []uint32
+	columns   []string
+}
+
+// WillReturnParamOIDs sets the parameter OIDs of the returned statement description. By default, every parameter
+// of the SQL is described as text.
+func (e *ExpectedPrepare) WillReturnParamOIDs(oids ...uint32) *ExpectedPrepare {
+	e.paramOIDs = oids
+	return e
+}
+
+// WillReturnColumns sets the columns of the field descriptions of the returned statement description.
+func (e *ExpectedPrepare) WillReturnColumns(columns ...string) *ExpectedPrepare {
+	e.columns = columns
+	return e
+}
+
+// WillReturnError allows to set an error for expected Prepare.
+func (e *ExpectedPrepare) WillReturnError(err error) *ExpectedPrepare {
+	e.err = err
+	return e
+}
+
+// Times arranges for the expectation to be met by n calls instead of one, which get the same answer.
+func (e *ExpectedPrepare) Times(n int) *ExpectedPrepare {
+	e.expectTimes(n)
+	return e
+}
+
+// WillDelayFor arranges for an expected Prepare to answer after d, or to fail like pgconn when its context is
+// done before.
+func (e *ExpectedPrepare) WillDelayFor(d time.Duration) *ExpectedPrepare {
+	e.delay = d
+	return e
+}
+
+// String returns string representation.
+func (e *ExpectedPrepare) String() string {
+	msg := fmt.Sprintf("ExpectedPrepare => expecting Prepare of statement '%s' which:", e.name)
+	msg += "\n  - matches sql: '" + e.expectSQL + "'"
+	if e.err != nil {
+		msg += fmt.Sprintf("\n  - should return error: %s", e.err)
+	}
+	return msg
+}
+
+func (e *ExpectedPrepare) expects(method string) bool {
+	return method == "Prepare"
+}
+
+func (e *ExpectedPrepare) match(call *mockCall, qm QueryMatcher) error {
+	if name, _ := call.args[1].(string); name != e.name {
+		return fmt.Errorf("statement name '%s' does not match expected '%s'", name, e.name)
+	}
+	return qm.Match(e.expectSQL, call.sql())
+}
+
+func (e *ExpectedPrepare) respond(call *mockCall) mock.Arguments {
+	if e.err != nil {
+		return mock.Arguments{nil, e.err}
+	}
+	sd := &pgconn.StatementDescription{
+		Name:      e.name,
+		SQL:       call.sql(),
+		ParamOIDs: e.paramOIDs,
+	}
+	if sd.ParamOIDs == nil {
+		sd.ParamOIDs = make([]uint32, countParams(sd.SQL))
+		for i := range sd.ParamOIDs {
+			sd.ParamOIDs[i] = pgtype.TextOID
+		}
+	}
+	for _, column := range e.columns {
+		sd.Fields = append(sd.Fields, pgproto3.FieldDescription{Name: []byte(column)})
+	}
+	e.state.prepare(e.name, sd.SQL)
+	return mock.Arguments{sd, nil}
+}
+
+// countParams returns the number of parameters of sql, which is the highest $n placeholder outside quotes.
+func countParams(sql string) int {
+	count := 0
+	var quote rune
+	for i := 0; i < len(sql); i++ {
+		c := rune(sql[i])
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '$':
+			n := 0
+			for i+1 < len(sql) && sql[i+1] >= '0' && sql[i+1] <= '9' {
+				i++
+				n = n*10 + int(sql[i]-'0')
+			}
+			if n > count {
+				count = n
+			}
+		}
+	}
+	return count
+}
+
+// ExpectedBegin is used to manage Pool.Begin, Pool.BeginTx and Tx.Begin expectations, and their BeginFunc and
+// BeginTxFunc variants. Returned by the ExpectBegin and ExpectBeginTx methods.
+type ExpectedBegin struct {
+	commonExpectation
+	opts *pgx.TxOptions
+	tx   *MockTx
+}
+
+// WillReturnTx specifies the transaction mock that will be returned by the triggered Begin. A new MockTx is returned
+// by every call when it is not set, which only accepts Commit and Rollback. The expectations of the transaction mock
+// share the sequence of the mock expecting Begin.
+func (e *ExpectedBegin) WillReturnTx(tx *MockTx) *ExpectedBegin {
+	e.tx = tx
+	e.state.adopt(tx.state())
+	return e
+}
+
+// WillReturnError allows to set an error for expected Begin.
+func (e *ExpectedBegin) WillReturnError(err error) *ExpectedBegin {
+	e.err = err
+	return e
+}
+
+// Times arranges for the expectation to be met by n calls instead of one, which get the same answer.
+func (e *ExpectedBegin) Times(n int) *ExpectedBegin {
+	e.expectTimes(n)
+	return e
+}
+
+// WillDelayFor arranges for an expected Begin to answer after d, or to fail like pgconn when its context is
+// done before.
+func (e *ExpectedBegin) WillDelayFor(d time.Duration) *ExpectedBegin {
+	e.delay = d
+	return e
+}
+
+// String returns string representation.
+func (e *ExpectedBegin) String() string {
+	msg := "ExpectedBegin => expecting Begin"
+	if e.opts != nil {
+		msg = fmt.Sprintf("ExpectedBegin => expecting BeginTx with options %+v", *e.opts)
+	}
+	if e.err != nil {
+		msg += fmt.Sprintf(", which should return error: %s", e.err)
+	}
+	return msg
+}
+
+func (e *ExpectedBegin) expects(method string) bool {
+	if e.opts != nil {
+		return method == "BeginTx" || method == "BeginTxFunc"
+	}
+	return method == "Begin" || method == "BeginTx" || method == "BeginFunc" || method == "BeginTxFunc"
+}
+
+func (e *ExpectedBegin) match(call *mockCall, _ QueryMatcher) error {
+	if e.opts == nil {
+		return nil
+	}
+	if opts, _ := call.args[1].(pgx.TxOptions); opts != *e.opts {
+		return fmt.Errorf("transaction options %+v do not match expected %+v", opts, *e.opts)
+	}
+	return nil
+}
+
+func (e *ExpectedBegin) respond(call *mockCall) mock.Arguments {
+	if e.err != nil {
+		ret, _ := errorArguments(call.method, e.err)
+		return ret
+	}
+	tx := e.tx
+	if tx == nil {
+		tx = &MockTx{}
+		e.state.adoptImplicit(tx.state())
+	}
+	switch call.method {
+	case "BeginFunc":
+		return mock.Arguments{func(ctx context.Context, f func(pgx.Tx) error) error {
+			return beginFunc(ctx, tx, f)
+		}}
+	case "BeginTxFunc":
+		return mock.Arguments{func(ctx context.Context, _ pgx.TxOptions, f func(pgx.Tx) error) error {
+			return beginFunc(ctx, tx, f)
+		}}
+	}
+	return mock.Arguments{tx, nil}
+}
+
+// beginFunc runs f in tx, and then commits tx, or rolls it back when f fails or panics, the way pgx.Conn.BeginFunc
+// does.
+func beginFunc(ctx context.Context, tx pgx.Tx, f func(pgx.Tx) error) (err error) {
+	defer func() {
+		rollbackErr := tx.Rollback(ctx)
+		if rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			err = rollbackErr
+		}
+	}()
+
+	fErr := f(tx)
+	if fErr != nil {
+		_ = tx.Rollback(ctx)
+		return fErr
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ExpectedCommit is used to manage Tx.Commit expectations. Returned by MockTx.ExpectCommit.
+type ExpectedCommit struct {
+	commonExpectation
+}
+
+// WillReturnError allows to set an error for expected Commit.
+func (e *ExpectedCommit) WillReturnError(err error) *ExpectedCommit {
+	e.err = err
+	return e
+}
+
+// Times arranges for the expectation to be met by n calls instead of one, which get the same answer.
+func (e *ExpectedCommit) Times(n int) *ExpectedCommit {
+	e.expectTimes(n)
+	return e
+}
+
+// WillDelayFor arranges for an expected Commit to answer after d, or to fail like pgconn when its context is
+// done before.
+func (e *ExpectedCommit) WillDelayFor(d time.Duration) *ExpectedCommit {
+	e.delay = d
+	return e
+}
+
+// String returns string representation.
+func (e *ExpectedCommit) String() string {
+	msg := "ExpectedCommit => expecting transaction Commit"
+	if e.err != nil {
+		msg += fmt.Sprintf(", which should return error: %s", e.err)
+	}
+	return msg
+}
+
+func (e *ExpectedCommit) expects(method string) bool {
+	return method == "Commit"
+}
+
+func (e *ExpectedCommit) match(_ *mockCall, _ QueryMatcher) error {
+	return nil
+}
+
+func (e *ExpectedCommit) respond(_ *mockCall) mock.Arguments {
+	return mock.Arguments{e.err}
+}
+
+// ExpectedRollback is used to manage Tx.Rollback expectations. Returned by MockTx.ExpectRollback.
+type ExpectedRollback struct {
+	commonExpectation
+}
+
+// WillReturnError allows to set an error for expected Rollback.
+func (e *ExpectedRollback) WillReturnError(err error) *ExpectedRollback {
+	e.err = err
+	return e
+}
+
+// Times arranges for the expectation to be met by n calls instead of one, which get the same answer.
+func (e *ExpectedRollback) Times(n int) *ExpectedRollback {
+	e.expectTimes(n)
+	return e
+}
+
+// WillDelayFor arranges for an expected Rollback to answer after d, or to fail like pgconn when its context is
+// done before.
+func (e *ExpectedRollback) WillDelayFor(d time.Duration) *ExpectedRollback {
+	e.delay = d
+	return e
+}
+
+// String returns string representation.
+func (e *ExpectedRollback) String() string {
+	msg := "ExpectedRollback => expecting transaction Rollback"
+	if e.err != nil {
+		msg += fmt.Sprintf(", which should return error: %s", e.err)
+	}
+	return msg
+}
+
+func (e *ExpectedRollback) expects(method string) bool {
+	return method == "Rollback"
+}
+
+func (e *ExpectedRollback) match(_ *mockCall, _ QueryMatcher) error {
+	return nil
+}
+
+func (e *ExpectedRollback) respond(_ *mockCall) mock.Arguments {
+	return mock.Arguments{e.err}
+}
+
+// ExpectedAcquire is used to manage ConnPool.AcquireConn and ConnPool.AcquireConnFunc expectations. Returned by
+// MockPool.ExpectAcquire.
+type ExpectedAcquire struct {
+	commonExpectation
+	conn *MockConn
+}
+
+// WillReturnConn specifies the connection mock that will be acquired by the triggered AcquireConn or AcquireConnFunc.
+// A new MockConn is returned by every call when it is not set, which only accepts Release. The expectations of the
+// connection mock share the sequence of the pool mock. AcquireConnFunc calls its Release once the function returns,
+// as pgxpool.Pool.AcquireFunc does, so Release is expected on it when it is set.
+func (e *ExpectedAcquire) WillReturnConn(conn *MockConn) *ExpectedAcquire {
+	e.conn = conn
+	e.state.adopt(conn.state())
+	return e
+}
+
+// WillReturnError allows to set an error for expected AcquireConn.
+func (e *ExpectedAcquire) WillReturnError(err error) *ExpectedAcquire {
+	e.err = err
+	return e
+}
+
+// Times arranges for the expectation to be met by n calls instead of one, which get the same answer.
+func (e *ExpectedAcquire) Times(n int) *ExpectedAcquire {
+	e.expectTimes(n)
+	return e
+}
+
+// WillDelayFor arranges for an expected AcquireConn to answer after d, or to fail like pgconn when its context is
+// done before.
+func (e *ExpectedAcquire) WillDelayFor(d time.Duration) *ExpectedAcquire {
+	e.delay = d
+	return e
+}
+
+// String returns string representation.
+func (e *ExpectedAcquire) String() string {
+	msg := "ExpectedAcquire => expecting AcquireConn or AcquireConnFunc"
+	if e.err != nil {
+		msg += fmt.Sprintf(", which should return error: %s", e.err)
+	}
+	return msg
+}
+
+func (e *ExpectedAcquire) expects(method string) bool {
+	return method == "AcquireConn" || method == "AcquireConnFunc"
+}
+
+func (e *ExpectedAcquire) match(_ *mockCall, _ QueryMatcher) error {
+	return nil
+}
+
+func (e *ExpectedAcquire) respond(call *mockCall) mock.Arguments {
+	if e.err != nil {
+		ret, _ := errorArguments(call.method, e.err)
+		return ret
+	}
+	conn := e.conn
+	if conn == nil {
+		conn = &MockConn{}
+		e.state.adoptImplicit(conn.state())
+	}
+	if call.method == "AcquireConnFunc" {
+		return mock.Arguments{func(_ context.Context, f func(Conn) error) error {
+			defer conn.Release()
+			return f(conn)
+		}}
+	}
+	return mock.Arguments{conn, nil}
+}
+
+// ExpectedAcquireAllIdle is used to manage ConnPool.AcquireAllIdleConns expectations. Returned by
+// MockPool.ExpectAcquireAllIdle.
+type ExpectedAcquireAllIdle struct {
+	commonExpectation
+	conns []Conn
+}
+
+// WillReturnConns specifies the idle connection mocks that will be acquired by the triggered AcquireAllIdleConns.
+// The expectations of the connection mocks share the sequence of the pool mock.
+func (e *ExpectedAcquireAllIdle) WillReturnConns(conns ...*MockConn) *ExpectedAcquireAllIdle {
+	e.conns = nil
+	for _, conn := range conns {
+		e.conns = append(e.conns, conn)
+		e.state.adopt(conn.state())
+	}
+	return e
+}
+
+// Times arranges for the expectation to be met by n calls instead of one, which get the same answer.
+func (e *ExpectedAcquireAllIdle) Times(n int) *ExpectedAcquireAllIdle {
+	e.expectTimes(n)
+	return e
+}
+
+// WillDelayFor arranges for an expected AcquireAllIdleConns to answer after d, or to return no connections when
+// its context is done before.
+func (e *ExpectedAcquireAllIdle) WillDelayFor(d time.Duration) *ExpectedAcquireAllIdle {
+	e.delay = d
+	return e
+}
+
+// String returns string representation.
+func (e *ExpectedAcquireAllIdle) String() string {
+	return fmt.Sprintf("ExpectedAcquireAllIdle => expecting AcquireAllIdleConns, which should return %d connections", len(e.conns))
+}
+
+func (e *ExpectedAcquireAllIdle) expects(method string) bool {
+	return method == "AcquireAllIdleConns"
+}
+
+func (e *ExpectedAcquireAllIdle) match(_ *mockCall, _ QueryMatcher) error {
+	return nil
+}
+
+func (e *ExpectedAcquireAllIdle) respond(_ *mockCall) mock.Arguments {
+	return mock.Arguments{e.conns}
+}
+
+// ExpectedRelease is used to manage Conn.Release expectations. Returned by MockConn.ExpectRelease.
+type ExpectedRelease struct {
+	commonExpectation
+}
+
+// Times arranges for the expectation to be met by n calls instead of one, which get the same answer.
+func (e *ExpectedRelease) Times(n int) *ExpectedRelease {
+	e.expectTimes(n)
+	return e
+}
+
+// WillDelayFor arranges for an expected Release to return after d.
+func (e *ExpectedRelease) WillDelayFor(d time.Duration) *ExpectedRelease {
+	e.delay = d
+	return e
+}
+
+// String returns string representation.
+func (e *ExpectedRelease) String() string {
+	return "ExpectedRelease => expecting connection Release"
+}
+
+func (e *ExpectedRelease) expects(method string) bool {
+	return method == "Release"
+}
+
+func (e *ExpectedRelease) match(_ *mockCall, _ QueryMatcher) error {
+	return nil
+}
+
+func (e *ExpectedRelease) respond(_ *mockCall) mock.Arguments {
+	return mock.Arguments{}
+}
+
+type errRow struct {
+	err error
+}
+
+func (er *errRow) Scan(_ ...interface{}) error {
+	return er.err
+}
+
+type errBatchResults struct {
+	err error
+}
+
+func (br *errBatchResults) Exec() (pgconn.CommandTag, error) {
+	return nil, br.err
+}
+
+func (br *errBatchResults) Query() (pgx.Rows, error) {
+	return nil, br.err
+}
+
+func (br *errBatchResults) QueryRow() pgx.Row {
+	return &errRow{err: br.err}
+}
+
+func (br *errBatchResults) QueryFunc(_ []interface{}, _ func(pgx.QueryFuncRow) error) (pgconn.CommandTag, error) {
+	return nil, br.err
+}
+
+func (br *errBatchResults) Close() error {
+	return br.err
+}
@@ -0,0 +1,566 @@
+package pgxpoolgo
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/mock"
+)
+
+const mockStateKey = "pgxpoolgo.mockState"
+
+var mockStateMu sync.Mutex
+
+// mockState holds the expectations registered with the Expect methods of a generated mock. It is kept in the
+// TestData of the mock, so the generated mocks stay untouched.
+type mockState struct {
+	name     string
+	mock     *mock.Mock
+	matcher  QueryMatcher
+	group    *mockGroup
+	parent   *mockState
+	children []*mockState
+	failures []string
+	handed   []verifier
+	tx       txStatus
+	prepared map[string]string
+	ordered  *bool
+	implicit bool
+}
+
+// mockGroup holds the expectations of a mock and of the mocks it handed out, in the order they were registered, and
+// counts their calls in flight.
+type mockGroup struct {
+	sync.Mutex
+	expected    []expectation
+	inFlight    int
+	maxInFlight int
+	limit       int
+}
+
+// verifier is a value handed out by a mock, which verifies how it was used by the code under test.
+type verifier interface {
+	verify() error
+}
+
+// mockCall is a call of a generated mock method. name is the prepared statement name the call was made with.
+type mockCall struct {
+	method string
+	args   []interface{}
+	name   string
+}
+
+// statementName matches the SQL of a call which can only be the name of a prepared statement, except for the
+// sqlCommands.
+var statementName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var sqlCommands = map[string]bool{
+	"abort": true, "analyze": true, "analyse": true, "begin": true, "checkpoint": true, "commit": true, "end": true,
+	"rollback": true, "start": true, "vacuum": true,
+}
+
+func stateOf(m *mock.Mock, name string) *mockState {
+	mockStateMu.Lock()
+	defer mockStateMu.Unlock()
+	data := m.TestData()
+	if s, ok := data[mockStateKey].(*mockState); ok {
+		return s
+	}
+	s := &mockState{
+		name:    name,
+		mock:    m,
+		matcher: QueryMatcherNormalized,
+		group:   &mockGroup{},
+	}
+	data[mockStateKey] = s
+	return s
+}
+
+// calledMethodSuffix matches the suffix of the function names of gccgo, which calledMethod trims like
+// mock.Mock.Called does.
+var calledMethodSuffix = regexp.MustCompile("\\.pN\\d+_")
+
+// calledMethod returns the name of the generated mock method calling the Called override of a mock, the same way
+// mock.Mock.Called does.
+func calledMethod() string {
+	pc, _, _, ok := runtime.Caller(2)
+	if !ok {
+		panic("Couldn't get the caller information")
+	}
+	functionPath := runtime.FuncForPC(pc).Name()
+	if calledMethodSuffix.MatchString(functionPath) {
+		functionPath = calledMethodSuffix.Split(functionPath, -1)[0]
+	}
+	parts := strings.Split(functionPath, ".")
+	return parts[len(parts)-1]
+}
+
+func (s *mockState) useQueryMatcher(qm QueryMatcher) {
+	defer s.lock().Unlock()
+	s.matcher = qm
+}
+
+func (s *mockState) matchInOrder(ordered bool) {
+	defer s.lock().Unlock()
+	s.ordered = &ordered
+}
+
+// inOrder tells whether the calls of the mock must match the next expectation of the group. A mock handed out
+// matches in order like the mock which handed it out, unless it was set otherwise.
+func (s *mockState) inOrder() bool {
+	for o := s; o != nil; o = o.parent {
+		if o.ordered != nil {
+			return *o.ordered
+		}
+	}
+	return true
+}
+
+func (s *mockState) expect(e expectation) {
+	defer s.lock().Unlock()
+	e.own(s)
+	s.group.expected = append(s.group.expected, e)
+}
+
+// enter counts a call of the mock in flight until the returned function is called.
+func (s *mockState) enter() func() {
+	group := s.lock()
+	defer group.Unlock()
+	group.inFlight++
+	if group.inFlight > group.maxInFlight {
+		group.maxInFlight = group.inFlight
+	}
+	return func() {
+		group.Lock()
+		defer group.Unlock()
+		group.inFlight--
+	}
+}
+
+// maxInFlight returns the most calls which were in flight at once, on the mock and on the mocks it handed out.
+func (s *mockState) maxInFlight() int {
+	defer s.lock().Unlock()
+	return s.group.maxInFlight
+}
+
+// expectMaxInFlight expects at most n calls in flight at once, on the mock and on the mocks it handed out.
+func (s *mockState) expectMaxInFlight(n int) {
+	if n < 1 {
+		panic("ExpectMaxInFlight: n must be positive")
+	}
+	defer s.lock().Unlock()
+	s.group.limit = n
+}
+
+// adopt links the state of a mock handed out by s, like the MockTx returned by Begin, so both share the sequence of
+// expectations.
+func (s *mockState) adopt(child *mockState) {
+	group := s.lock()
+	defer group.Unlock()
+	if child.groupOf() == group {
+		return
+	}
+	childGroup := child.lock()
+	defer childGroup.Unlock()
+	group.expected = append(group.expected, childGroup.expected...)
+	mockStateMu.Lock()
+	child.join(group)
+	mockStateMu.Unlock()
+	child.parent = s
+	s.children = append(s.children, child)
+}
+
+// adoptImplicit adopts the state of a mock an expectation hands out because none was set, like the MockTx returned
+// by Begin without WillReturnTx. Nothing can be expected of it, so it answers the calls ending it, Commit, Rollback and
+// Release, without expectations.
+func (s *mockState) adoptImplicit(child *mockState) {
+	child.implicit = true
+	s.adopt(child)
+}
+
+// join moves the state, and the states it handed out, to group. It is called with mockStateMu, the group and the
+// former group of the state locked.
+func (s *mockState) join(group *mockGroup) {
+	s.group = group
+	for _, child := range s.children {
+		child.join(group)
+	}
+}
+
+// groupOf returns the group of the state, which adopt replaces.
+func (s *mockState) groupOf() *mockGroup {
+	mockStateMu.Lock()
+	defer mockStateMu.Unlock()
+	return s.group
+}
+
+// lock locks the group of the state and returns it. The fields of the state and of its group are only used with
+// the group locked.
+func (s *mockState) lock() *mockGroup {
+	for {
+		group := s.groupOf()
+		group.Lock()
+		if s.groupOf() == group {
+			return group
+		}
+		group.Unlock()
+	}
+}
+
+// owns tells whether the expectation was registered on the mock of s.
+func (s *mockState) owns(e expectation) bool {
+	return e.owner() == s
+}
+
+// descends tells whether the expectation was registered on the mock of s or on a mock it handed out.
+func (s *mockState) descends(e expectation) bool {
+	for o := e.owner(); o != nil; o = o.parent {
+		if o == s {
+			return true
+		}
+	}
+	return false
+}
+
+// called answers a call from the registered expectations. It returns false when the call should be answered by the
+// testify expectations instead, which is the case when no expectation was registered with the Expect methods, or
+// when none of them matches but a testify expectation for the method exists.
+func (s *mockState) called(method string, args []interface{}) (mock.Arguments, bool) {
+	return s.calledAs([]string{method}, args)
+}
+
+// calledAs answers a call which can be made as any of methods, like a query received by a MockServer, which is an
+// Exec or a Query. The expectation is found and triggered in one step, so concurrent calls never answer from the
+// same one. The call fails as the first of methods when no expectation matches.
+func (s *mockState) calledAs(methods []string, args []interface{}) (mock.Arguments, bool) {
+	ret, ok := errorArguments(methods[0], nil)
+	if !ok {
+		return nil, false
+	}
+	if s.implicit && (methods[0] == "Commit" || methods[0] == "Rollback" || methods[0] == "Release") {
+		return ret, true
+	}
+	group := s.lock()
+	if !s.expects() {
+		group.Unlock()
+		return nil, false
+	}
+	var call *mockCall
+	var e expectation
+	var err error
+	for i, method := range methods {
+		c := &mockCall{method: method, args: args}
+		var found expectation
+		findErr := s.resolve(c)
+		if findErr == nil {
+			found, findErr = s.find(c)
+		}
+		if findErr == nil {
+			call, e, err = c, found, nil
+			break
+		}
+		if i == 0 {
+			call, err = c, findErr
+		}
+	}
+	if err != nil {
+		if s.hasOn(call.method) {
+			group.Unlock()
+			return nil, false
+		}
+		s.failures = append(s.failures, err.Error())
+		group.Unlock()
+		ret, _ := errorArguments(call.method, err)
+		return ret, true
+	}
+	e.trigger()
+	group.Unlock()
+	if err := e.wait(callContext(args)); err != nil {
+		ret, _ := errorArguments(call.method, err)
+		return ret, true
+	}
+	return e.respond(call), true
+}
+
+// prepare registers the SQL of the statement prepared as name, to be executed with the name as SQL.
+func (s *mockState) prepare(name, sql string) {
+	defer s.lock().Unlock()
+	if s.prepared == nil {
+		s.prepared = make(map[string]string)
+	}
+	s.prepared[name] = sql
+}
+
+// resolve replaces the SQL of a call made with the name of a prepared statement by the SQL of the statement, the
+// way pgx executes prepared statements. It fails when the SQL of the call is a name which was never prepared.
+func (s *mockState) resolve(call *mockCall) error {
+	if !call.hasSQL() || call.method == "Prepare" {
+		return nil
+	}
+	name := call.sql()
+	if sql, ok := s.prepared[name]; ok {
+		args := append([]interface{}(nil), call.args...)
+		args[1] = sql
+		call.args = args
+		call.name = name
+		return nil
+	}
+	if statementName.MatchString(name) && !sqlCommands[strings.ToLower(name)] {
+		return fmt.Errorf("%s: call to %s was not expected, prepared statement \"%s\" does not exist", s.name, call, name)
+	}
+	return nil
+}
+
+// fail records an error found while answering a call, to be reported by ExpectationsWereMet.
+func (s *mockState) fail(err error) {
+	defer s.lock().Unlock()
+	s.failures = append(s.failures, fmt.Sprintf("%s: %s", s.name, err))
+}
+
+// returned keeps track of the values handed out by a call, answered by the expectations or by On, so they can be
+// verified by ExpectationsWereMet.
+func (s *mockState) returned(method string, args []interface{}, ret mock.Arguments) {
+	if len(ret) == 0 {
+		return
+	}
+	defer s.lock().Unlock()
+	switch v := ret.Get(0).(type) {
+	case *batchResults:
+		if method == "SendBatch" {
+			v.bind(args[1].(*pgx.Batch))
+			s.handed = append(s.handed, v)
+		}
+	case *MockTx:
+		if method == "Begin" || method == "BeginTx" {
+			s.handed = append(s.handed, v.state())
+		}
+	case *rows:
+		if method == "Query" {
+			sql, _ := args[1].(string)
+			v.bind(sql)
+			s.handed = append(s.handed, v)
+		}
+	}
+}
+
+// returnedError returns the error returned by a call, which is the last returned value, or the error of the
+// pgx.Row or pgx.BatchResults returned by QueryRow or SendBatch when the call failed.
+func returnedError(ret mock.Arguments) error {
+	if len(ret) == 0 {
+		return nil
+	}
+	switch v := ret.Get(len(ret) - 1).(type) {
+	case error:
+		return v
+	case *errRow:
+		return v.err
+	case *errBatchResults:
+		return v.err
+	}
+	return nil
+}
+
+func (s *mockState) expects() bool {
+	return len(s.group.expected) > 0
+}
+
+func (s *mockState) find(call *mockCall) (expectation, error) {
+	for _, e := range s.group.expected {
+		if e.fulfilled() {
+			continue
+		}
+		if s.owns(e) && e.expects(call.method) && e.match(call, s.matcher) == nil {
+			return e, nil
+		}
+		if s.inOrder() {
+			return nil, s.unexpected(call, e)
+		}
+	}
+	return nil, s.unexpected(call, nil)
+}
+
+// unexpected builds the error of a call matching no expectation. In order, next is the expectation the call was
+// expected to match. The error includes the closest expected query when the call has SQL.
+func (s *mockState) unexpected(call *mockCall, next expectation) error {
+	msg := fmt.Sprintf("%s: call to %s was not expected", s.name, call)
+	candidates := []expectation{next}
+	if next == nil {
+		candidates = nil
+		for _, e := range s.group.expected {
+			if !e.fulfilled() && s.owns(e) {
+				candidates = append(candidates, e)
+			}
+		}
+		if len(candidates) == 0 {
+			return errors.New("all expectations were already fulfilled, " + msg)
+		}
+	} else {
+		msg += fmt.Sprintf(", next expectation is: %s: %s", next.owner().name, next)
+	}
+	var closest expectation
+	distance := -1
+	for _, e := range candidates {
+		if !s.owns(e) || !e.expects(call.method) {
+			continue
+		}
+		q, ok := e.(interface{ expectedSQL() string })
+		if !ok || !call.hasSQL() {
+			if closest == nil {
+				closest = e
+			}
+			continue
+		}
+		if d := queryDistance(q.expectedSQL(), call.sql()); distance < 0 || d < distance {
+			closest, distance = e, d
+		}
+	}
+	if closest != nil {
+		if next == nil {
+			msg += fmt.Sprintf(", closest expectation is %s", closest)
+		}
+		if err := closest.match(call, s.matcher); err != nil {
+			msg += fmt.Sprintf("\n  - which does not match: %s", err)
+		}
+		if distance > 0 {
+			msg += "\n" + queryDiff(closest.(interface{ expectedSQL() string }).expectedSQL(), call.sql())
+		}
+	}
+	return errors.New(msg)
+}
+
+// hasOn reports whether a testify expectation was registered for method with On. It reads ExpectedCalls without the
+// lock of testify, which does not export it, so On must not be called while the mock is called concurrently.
+func (s *mockState) hasOn(method string) bool {
+	for _, c := range s.mock.ExpectedCalls {
+		if c.Method == method {
+			return true
+		}
+	}
+	return false
+}
+
+// expectationsWereMet checks the expectations of the mock and of every mock it handed out.
+func (s *mockState) expectationsWereMet() error {
+	defer s.lock().Unlock()
+	var msgs []string
+	s.walk(func(d *mockState) {
+		msgs = append(msgs, d.failures...)
+		for _, v := range d.handed {
+			if err := v.verify(); err != nil {
+				msgs = append(msgs, fmt.Sprintf("%s: %s", d.name, err))
+			}
+		}
+	})
+	for _, e := range s.group.expected {
+		if !e.fulfilled() && s.descends(e) {
+			msg := fmt.Sprintf("%s: there is a remaining expectation which was not matched: %s", e.owner().name, e)
+			if calls, times := e.progress(); times > 1 {
+				msg += fmt.Sprintf("\n  - which was called %d of %d times", calls, times)
+			}
+			msgs = append(msgs, msg)
+		}
+	}
+	if s.group.limit > 0 && s.group.maxInFlight > s.group.limit {
+		msgs = append(msgs, fmt.Sprintf("%s: %d calls were in flight at once, but at most %d were expected", s.name, s.group.maxInFlight, s.group.limit))
+	}
+	if len(msgs) > 0 {
+		return errors.New(strings.Join(msgs, "\n"))
+	}
+	return nil
+}
+
+func (s *mockState) walk(f func(*mockState)) {
+	f(s)
+	for _, child := range s.children {
+		child.walk(f)
+	}
+}
+
+func (s *mockState) assertExpectations(t mock.TestingT) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	if err := s.expectationsWereMet(); err != nil {
+		t.Errorf("%s", err)
+		return false
+	}
+	return true
+}
+
+// errorArguments returns the arguments a generated mock method expects when the call fails with err. It returns
+// false for the methods the expectations do not support.
+func errorArguments(method string, err error) (mock.Arguments, bool) {
+	switch method {
+	case "Exec", "Query", "QueryFunc", "Begin", "BeginTx", "AcquireConn", "Prepare":
+		return mock.Arguments{nil, err}, true
+	case "Commit", "Rollback", "BeginFunc", "BeginTxFunc", "AcquireConnFunc":
+		return mock.Arguments{err}, true
+	case "AcquireAllIdleConns":
+		return mock.Arguments{nil}, true
+	case "Release":
+		return mock.Arguments{}, true
+	case "QueryRow":
+		return mock.Arguments{&errRow{err: err}}, true
+	case "SendBatch":
+		return mock.Arguments{&errBatchResults{err: err}}, true
+	case "CopyFrom":
+		return mock.Arguments{int64(0), err}, true
+	}
+	return nil, false
+}
+
+func (c *mockCall) hasSQL() bool {
+	switch c.method {
+	case "Exec", "Query", "QueryRow", "QueryFunc", "Prepare":
+		return true
+	}
+	return false
+}
+
+func (c *mockCall) sql() string {
+	if !c.hasSQL() {
+		return ""
+	}
+	i := 1
+	if c.method == "Prepare" {
+		i = 2
+	}
+	sql, _ := c.args[i].(string)
+	return sql
+}
+
+func (c *mockCall) queryArgs() []interface{} {
+	switch c.method {
+	case "QueryFunc":
+		args, _ := c.args[2].([]interface{})
+		return args
+	case "Prepare":
+		return nil
+	}
+	if c.hasSQL() {
+		return c.args[2:]
+	}
+	return nil
+}
+
+// String returns string representation.
+func (c *mockCall) String() string {
+	if c.method == "Prepare" {
+		return fmt.Sprintf("%s '%s' as '%s'", c.method, stripQuery(c.sql()), c.args[1])
+	}
+	if c.name != "" {
+		return fmt.Sprintf("%s of prepared statement '%s' ('%s') with args %+v", c.method, c.name, stripQuery(c.sql()), c.queryArgs())
+	}
+	if c.hasSQL() {
+		return fmt.Sprintf("%s '%s' with args %+v", c.method, stripQuery(c.sql()), c.queryArgs())
+	}
+	if len(c.args) > 1 {
+		return fmt.Sprintf("%s with args %+v", c.method, c.args[1:])
+	}
+	return c.method
+}
@@ -0,0 +1,337 @@
+package pgxpoolgo
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"sync"
+)
+
+// FakePool is a Pool which runs a subset of SQL on in-memory tables, for the tests which check that the data they
+// write can be read back rather than the exact SQL. It understands:
+//
+//   - CREATE TABLE [IF NOT EXISTS] with the types of pgtype, serial columns, NOT NULL, DEFAULT, and PRIMARY KEY and
+//     UNIQUE constraints of columns or of the table, DROP TABLE [IF EXISTS] and TRUNCATE
+//   - INSERT INTO table [(columns)] VALUES (...), ... [ON CONFLICT [(columns)] DO NOTHING | DO UPDATE SET ...
+//     [WHERE ...]], where EXCLUDED is the row which conflicted
+//   - SELECT items [FROM table [alias]] [WHERE ...] [ORDER BY ... [ASC | DESC] [NULLS FIRST | LAST]] [LIMIT n]
+//     [OFFSET n], where items can be the aggregates count, sum, min, max and avg of all the rows
+//   - UPDATE table [alias] SET ... [WHERE ...] and DELETE FROM table [alias] [WHERE ...]
+//   - RETURNING items, for INSERT, UPDATE and DELETE
+//
+// Expressions are $n parameters, literals, columns, comparisons, AND, OR, NOT, IS [NOT] NULL, [NOT] IN, [NOT]
+// BETWEEN, [NOT] LIKE, [NOT] ILIKE, arithmetic, ||, casts with ::, and the functions now, coalesce, lower, upper and
+// length. Joins, subqueries and GROUP BY are not supported. The errors are *pgconn.PgError with the codes
+// PostgreSQL uses, like 23505 for a duplicate key, which ErrDB reads.
+//
+// Every statement, batch and CopyFrom is atomic. Transactions see a snapshot of the tables taken by Begin, and
+// publish their changes on Commit, merged with the rows other transactions changed since. Commit fails, rolling
+// back, with 40001 when a row the transaction updated or deleted was also updated or deleted since Begin, or a table
+// it created, dropped or indexed also changed, and with 23505 when a row it wrote has the key of a row written since.
+// Serial columns draw from sequences which, like those of PostgreSQL, never roll back. Acquire and AcquireFunc fail,
+// and Config and Stat return nil.
+type FakePool struct {
+	fakeConn
+	mu     sync.Mutex
+	store  *fakeStore
+	closed bool
+}
+
+// fakeConn runs the statements of a FakePool or of its transactions with do, which calls f with the tables they
+// see.
+type fakeConn struct {
+	do       func(f func(s *fakeStore, readOnly bool) error) error
+	prepared map[string]string
+}
+
+// NewFakePool returns a FakePool without tables.
+func NewFakePool() *FakePool {
+	p := &FakePool{store: newFakeStore()}
+	p.fakeConn = fakeConn{do: p.do}
+	return p
+}
+
+func (p *FakePool) do(f func(s *fakeStore, readOnly bool) error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return errors.New("closed pool")
+	}
+	return f(p.store, false)
+}
+
+func (p *FakePool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+}
+
+func (p *FakePool) Acquire(_ context.Context) (*pgxpool.Conn, error) {
+	return nil, errors.New("FakePool: Acquire is not supported")
+}
+
+func (p *FakePool) AcquireFunc(_ context.Context, _ func(*pgxpool.Conn) error) error {
+	return errors.New("FakePool: AcquireFunc is not supported")
+}
+
+func (p *FakePool) AcquireAllIdle(_ context.Context) []*pgxpool.Conn {
+	return nil
+}
+
+func (p *FakePool) Config() *pgxpool.Config {
+	return nil
+}
+
+func (p *FakePool) Stat() *pgxpool.Stat {
+	return nil
+}
+
+func (p *FakePool) Begin(ctx context.Context) (pgx.Tx, error) {
+	return p.BeginTx(ctx, pgx.TxOptions{})
+}
+
+// BeginTx begins a transaction. Its isolation is always a snapshot of the tables, and the ReadOnly access mode
+// makes the statements which write fail.
+func (p *FakePool) BeginTx(_ context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	var tx *fakeTx
+	err := p.do(func(s *fakeStore, _ bool) error {
+		tx = newFakeTx(p, nil, s, txOptions.AccessMode == pgx.ReadOnly, make(map[string]string))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (p *FakePool) BeginFunc(ctx context.Context, f func(pgx.Tx) error) error {
+	return poolBeginFunc(ctx, p.Begin, f)
+}
+
+func (p *FakePool) BeginTxFunc(ctx context.Context, txOptions pgx.TxOptions, f func(pgx.Tx) error) error {
+	return poolBeginFunc(ctx, func(ctx context.Context) (pgx.Tx, error) {
+		return p.BeginTx(ctx, txOptions)
+	}, f)
+}
+
+func (p *FakePool) Ping(_ context.Context) error {
+	return p.do(func(*fakeStore, bool) error {
+		return nil
+	})
+}
+
+func (c *fakeConn) exec(sql string, args []interface{}) (*fakeResult, error) {
+	if prepared, ok := c.prepared[sql]; ok {
+		sql = prepared
+	}
+	var res *fakeResult
+	err := c.do(func(s *fakeStore, readOnly bool) (err error) {
+		res, err = s.exec(sql, args, readOnly)
+		return err
+	})
+	return res, err
+}
+
+func (c *fakeConn) Exec(_ context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	res, err := c.exec(sql, arguments)
+	if err != nil {
+		return nil, err
+	}
+	return res.tag, nil
+}
+
+func (c *fakeConn) Query(_ context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	res, err := c.exec(sql, args)
+	if err != nil {
+		return nil, err
+	}
+	return res.mockRows().Compose(), nil
+}
+
+func (c *fakeConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	rows, err := c.Query(ctx, sql, args...)
+	return &firstRow{rows: rows, err: err}
+}
+
+func (c *fakeConn) QueryFunc(ctx context.Context, sql string, args []interface{}, scans []interface{}, f func(pgx.QueryFuncRow) error) (pgconn.CommandTag, error) {
+	rows, err := c.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return queryFunc(rows, scans, f)
+}
+
+// SendBatch runs the queued queries of b, all of them or none. The results of the query which fails, and of the
+// queries after it, return its error.
+func (c *fakeConn) SendBatch(_ context.Context, b *pgx.Batch) pgx.BatchResults {
+	queries := queuedQueries(b)
+	results := make([]func() (pgx.Rows, error), len(queries))
+	err := c.do(func(s *fakeStore, readOnly bool) error {
+		batch := &fakeStore{tables: s.snapshot()}
+		for i, q := range queries {
+			sql := q.sql
+			if prepared, ok := c.prepared[sql]; ok {
+				sql = prepared
+			}
+			res, err := batch.exec(sql, q.args, readOnly)
+			if err != nil {
+				return err
+			}
+			results[i] = func() (pgx.Rows, error) {
+				return res.mockRows().Compose(), nil
+			}
+		}
+		s.tables = batch.tables
+		return nil
+	})
+	for i := range results {
+		if results[i] == nil {
+			results[i] = func() (pgx.Rows, error) {
+				return nil, err
+			}
+		}
+	}
+	return &rowsBatchResults{results: results, err: err}
+}
+
+// CopyFrom inserts the rows of rowSrc into the table tableName, whose schema is ignored, all of them or none.
+func (c *fakeConn) CopyFrom(_ context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	var rows [][]interface{}
+	for rowSrc.Next() {
+		values, err := rowSrc.Values()
+		if err != nil {
+			return 0, err
+		}
+		rows = append(rows, append([]interface{}(nil), values...))
+	}
+	if err := rowSrc.Err(); err != nil {
+		return 0, err
+	}
+	var res *fakeResult
+	err := c.do(func(s *fakeStore, readOnly bool) (err error) {
+		if readOnly {
+			return fakeError("25006", "cannot execute COPY FROM in a read-only transaction")
+		}
+		res, err = s.copyFrom(tableName[len(tableName)-1], columnNames, rows)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.tag.RowsAffected(), nil
+}
+
+// fakeTx is a transaction of a FakePool, or a savepoint of the transaction parent. It runs its statements on the
+// snapshot store of the tables, taken as base, and merges the tables it changed into its pool or parent on Commit.
+type fakeTx struct {
+	fakeConn
+	pool     *FakePool
+	parent   *fakeTx
+	base     map[string]*fakeTable
+	store    *fakeStore
+	status   txStatus
+	readOnly bool
+}
+
+func newFakeTx(pool *FakePool, parent *fakeTx, s *fakeStore, readOnly bool, prepared map[string]string) *fakeTx {
+	tx := &fakeTx{pool: pool, parent: parent, base: s.snapshot(), store: &fakeStore{tables: s.snapshot()}, readOnly: readOnly}
+	tx.fakeConn = fakeConn{do: tx.do, prepared: prepared}
+	return tx
+}
+
+// do runs f on the tables of the transaction, which fails when f fails.
+func (t *fakeTx) do(f func(s *fakeStore, readOnly bool) error) error {
+	switch t.status {
+	case txCommitted, txRolledBack:
+		return pgx.ErrTxClosed
+	case txFailed:
+		return errTxAborted
+	}
+	if err := f(t.store, t.readOnly); err != nil {
+		t.status = txFailed
+		return err
+	}
+	return nil
+}
+
+// Begin begins a savepoint of the transaction.
+func (t *fakeTx) Begin(_ context.Context) (pgx.Tx, error) {
+	var sp *fakeTx
+	err := t.do(func(s *fakeStore, readOnly bool) error {
+		sp = newFakeTx(t.pool, t, s, readOnly, t.prepared)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+func (t *fakeTx) BeginFunc(ctx context.Context, f func(pgx.Tx) error) error {
+	return poolBeginFunc(ctx, t.Begin, f)
+}
+
+// Commit publishes the tables the transaction changed. It rolls back a failed transaction, and fails with
+// pgx.ErrTxCommitRollback.
+func (t *fakeTx) Commit(_ context.Context) error {
+	switch t.status {
+	case txCommitted, txRolledBack:
+		return pgx.ErrTxClosed
+	case txFailed:
+		t.status = txRolledBack
+		return pgx.ErrTxCommitRollback
+	}
+	var err error
+	if t.parent != nil {
+		err = t.parent.do(func(s *fakeStore, _ bool) error {
+			return s.merge(t.base, t.store.tables)
+		})
+	} else {
+		t.pool.mu.Lock()
+		err = t.pool.store.merge(t.base, t.store.tables)
+		t.pool.mu.Unlock()
+	}
+	if err != nil {
+		t.status = txRolledBack
+		return err
+	}
+	t.status = txCommitted
+	return nil
+}
+
+func (t *fakeTx) Rollback(_ context.Context) error {
+	if t.status == txCommitted || t.status == txRolledBack {
+		return pgx.ErrTxClosed
+	}
+	t.status = txRolledBack
+	return nil
+}
+
+// Prepare parses sql, which Exec, Query, QueryRow, QueryFunc and SendBatch run when they are given name. The
+// parameters are described as text.
+func (t *fakeTx) Prepare(_ context.Context, name string, sql string) (*pgconn.StatementDescription, error) {
+	err := t.do(func(*fakeStore, bool) error {
+		_, err := fakeParse(sql)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	t.prepared[name] = sql
+	paramOIDs := make([]uint32, countParams(sql))
+	for i := range paramOIDs {
+		paramOIDs[i] = pgtype.TextOID
+	}
+	return &pgconn.StatementDescription{Name: name, SQL: sql, ParamOIDs: paramOIDs}, nil
+}
+
+func (t *fakeTx) LargeObjects() pgx.LargeObjects {
+	return pgx.LargeObjects{}
+}
+
+func (t *fakeTx) Conn() *pgx.Conn {
+	return nil
+}
@@ -0,0 +1,1552 @@
+package pgxpoolgo
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgtype"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeTable is a table of a FakePool. Tables, and their rows, are copied on write, so a snapshot of the tables is a
+// copy of their map, and a table or a row which changed since a snapshot is another *fakeTable or row.
+type fakeTable struct {
+	name        string
+	columns     []fakeColumn
+	constraints []fakeConstraint
+	rows        [][]interface{}
+	serials     *fakeSequences
+}
+
+// fakeSequences are the sequences of the serial columns of a table. They are shared by the copies of the table, so
+// like PostgreSQL sequences they never go back when a statement fails or a transaction rolls back.
+type fakeSequences struct {
+	mu     sync.Mutex
+	values []int64
+}
+
+// next returns the next value of the sequence of the column at the index c.
+func (seq *fakeSequences) next(c int) int64 {
+	seq.mu.Lock()
+	defer seq.mu.Unlock()
+	seq.values[c]++
+	return seq.values[c]
+}
+
+type fakeColumn struct {
+	name    string
+	dt      *pgtype.DataType
+	notNull bool
+	serial  bool
+	def     fakeExpr
+}
+
+// fakeConstraint is the primary key or a unique constraint of a table, over the columns at the indexes columns.
+type fakeConstraint struct {
+	name    string
+	columns []int
+}
+
+// fakeStore holds the tables of a FakePool, or the tables a transaction sees.
+type fakeStore struct {
+	tables map[string]*fakeTable
+}
+
+// fakeResult is the result of a statement.
+type fakeResult struct {
+	columns []string
+	dts     []*pgtype.DataType
+	rows    [][]interface{}
+	tag     pgconn.CommandTag
+}
+
+// fakeScope is a row a statement reads the columns of. The columns of a qualified scope, like EXCLUDED, are only
+// read qualified by its name.
+type fakeScope struct {
+	name      string
+	alias     string
+	qualified bool
+	table     *fakeTable
+	row       []interface{}
+}
+
+// fakeEnv evaluates the expressions of a statement.
+type fakeEnv struct {
+	ci     *pgtype.ConnInfo
+	args   []interface{}
+	now    time.Time
+	scopes []fakeScope
+}
+
+// fakeOutput is a column of the result of SELECT or RETURNING.
+type fakeOutput struct {
+	name string
+	expr fakeExpr
+	dt   *pgtype.DataType
+}
+
+var errSerializationFailure = &pgconn.PgError{
+	Severity: "ERROR",
+	Code:     errCodeSerializationFailure,
+	Message:  "could not serialize access due to concurrent update",
+}
+
+func fakeError(code string, format string, args ...interface{}) error {
+	return &pgconn.PgError{Severity: "ERROR", Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{tables: make(map[string]*fakeTable)}
+}
+
+// snapshot returns a copy of the tables of s.
+func (s *fakeStore) snapshot() map[string]*fakeTable {
+	tables := make(map[string]*fakeTable, len(s.tables))
+	for name, t := range s.tables {
+		tables[name] = t
+	}
+	return tables
+}
+
+// merge publishes into s the tables which changed from base to tables. A table which also changed in s since base is
+// merged row by row by mergeRows. It fails, without publishing any table, when one of them cannot be merged.
+func (s *fakeStore) merge(base map[string]*fakeTable, tables map[string]*fakeTable) error {
+	changed := make(map[string]*fakeTable)
+	for name, t := range tables {
+		if base[name] != t {
+			changed[name] = t
+		}
+	}
+	for name := range base {
+		if _, ok := tables[name]; !ok {
+			changed[name] = nil
+		}
+	}
+	for name, t := range changed {
+		if s.tables[name] == base[name] {
+			continue
+		}
+		merged, err := mergeRows(base[name], t, s.tables[name])
+		if err != nil {
+			return err
+		}
+		changed[name] = merged
+	}
+	for name, t := range changed {
+		if t == nil {
+			delete(s.tables, name)
+		} else {
+			s.tables[name] = t
+		}
+	}
+	return nil
+}
+
+// mergeRows merges the rows a transaction changed from base to t into current, the table as it is now, like
+// concurrent transactions of PostgreSQL which change other rows. It fails with 40001 when a row the transaction
+// updated or deleted was also updated or deleted, or when the table was created, dropped or indexed by one of them,
+// and with 23505 when a row the transaction wrote has the key of a row written since.
+func mergeRows(base, t, current *fakeTable) (*fakeTable, error) {
+	if base == nil || t == nil || current == nil || !base.sameSchema(t) || !base.sameSchema(current) {
+		return nil, errSerializationFailure
+	}
+	rows := func(t *fakeTable) map[*interface{}]bool {
+		ids := make(map[*interface{}]bool, len(t.rows))
+		for _, row := range t.rows {
+			ids[&row[0]] = true
+		}
+		return ids
+	}
+	baseRows, txRows, currentRows := rows(base), rows(t), rows(current)
+	for id := range baseRows {
+		if !txRows[id] && !currentRows[id] {
+			return nil, errSerializationFailure
+		}
+	}
+	merged := current.clone()
+	merged.rows = nil
+	for _, row := range current.rows {
+		if !baseRows[&row[0]] || txRows[&row[0]] {
+			merged.rows = append(merged.rows, row)
+		}
+	}
+	ci := pgtype.NewConnInfo()
+	for _, row := range t.rows {
+		if baseRows[&row[0]] {
+			continue
+		}
+		if _, c := merged.conflict(ci, row, -1); c != nil {
+			return nil, merged.duplicateKey(ci, c, row)
+		}
+		merged.rows = append(merged.rows, row)
+	}
+	return merged, nil
+}
+
+// sameSchema reports whether t and other are copies of the same table, with the same constraints. A table without
+// columns is never the same, its rows having nothing to tell them apart.
+func (t *fakeTable) sameSchema(other *fakeTable) bool {
+	if len(t.columns) == 0 || len(t.columns) != len(other.columns) || &t.columns[0] != &other.columns[0] {
+		return false
+	}
+	if len(t.constraints) != len(other.constraints) {
+		return false
+	}
+	return len(t.constraints) == 0 || &t.constraints[0] == &other.constraints[0]
+}
+
+// exec runs the statement sql with args on s.
+func (s *fakeStore) exec(sql string, args []interface{}, readOnly bool) (*fakeResult, error) {
+	stmt, err := fakeParse(sql)
+	if err != nil {
+		return nil, err
+	}
+	if n := countParams(sql); n > len(args) {
+		return nil, fakeError("08P01", "bind message supplies %d parameters, but prepared statement \"\" requires %d", len(args), n)
+	}
+	if _, ok := stmt.(*fakeSelect); !ok && readOnly {
+		return nil, fakeError("25006", "cannot execute %s in a read-only transaction", strings.ToUpper(strings.Fields(sql)[0]))
+	}
+	env := newFakeEnv(args)
+	switch stmt := stmt.(type) {
+	case *fakeCreateTable:
+		return s.createTable(env, stmt)
+	case *fakeCreateIndex:
+		return s.createIndex(env, stmt)
+	case *fakeDropTable:
+		return s.dropTable(stmt)
+	case *fakeTruncate:
+		return s.truncate(stmt)
+	case *fakeInsert:
+		return s.insert(env, stmt)
+	case *fakeSelect:
+		return s.selectRows(env, stmt)
+	case *fakeUpdate:
+		return s.update(env, stmt)
+	default:
+		return s.delete(env, stmt.(*fakeDelete))
+	}
+}
+
+func (s *fakeStore) table(name string) (*fakeTable, error) {
+	t, ok := s.tables[name]
+	if !ok {
+		return nil, fakeError(errCodeUndefinedTable, "relation \"%s\" does not exist", name)
+	}
+	return t, nil
+}
+
+func (s *fakeStore) createTable(env *fakeEnv, stmt *fakeCreateTable) (*fakeResult, error) {
+	tag := &fakeResult{tag: pgconn.CommandTag("CREATE TABLE")}
+	if _, ok := s.tables[stmt.table]; ok {
+		if stmt.ifNotExists {
+			return tag, nil
+		}
+		return nil, fakeError(errCodeDuplicateTable, "relation \"%s\" already exists", stmt.table)
+	}
+	t := &fakeTable{name: stmt.table}
+	primaryKey := stmt.primaryKey
+	var uniques [][]string
+	for _, def := range stmt.columns {
+		if t.column(def.name) >= 0 {
+			return nil, fakeError("42701", "column \"%s\" specified more than once", def.name)
+		}
+		dt, ok := dataTypeForName(env.ci, def.typeName)
+		if !ok {
+			return nil, fakeError("42704", "type \"%s\" does not exist", def.typeName)
+		}
+		t.columns = append(t.columns, fakeColumn{name: def.name, dt: dt, notNull: def.notNull, serial: def.serial, def: def.def})
+		if def.primary {
+			if primaryKey != nil {
+				return nil, fakeError("42P16", "multiple primary keys for table \"%s\" are not allowed", stmt.table)
+			}
+			primaryKey = []string{def.name}
+		}
+		if def.unique {
+			uniques = append(uniques, []string{def.name})
+		}
+	}
+	if primaryKey != nil {
+		if err := t.addConstraint(t.name+"_pkey", primaryKey); err != nil {
+			return nil, err
+		}
+		for _, c := range t.constraints[0].columns {
+			t.columns[c].notNull = true
+		}
+	}
+	for _, unique := range append(uniques, stmt.uniques...) {
+		if err := t.addConstraint(t.name+"_"+strings.Join(unique, "_")+"_key", unique); err != nil {
+			return nil, err
+		}
+	}
+	t.serials = &fakeSequences{values: make([]int64, len(t.columns))}
+	s.tables[t.name] = t
+	return tag, nil
+}
+
+// createIndex adds a unique index as a constraint of its table. Other indexes are ignored.
+func (s *fakeStore) createIndex(env *fakeEnv, stmt *fakeCreateIndex) (*fakeResult, error) {
+	t, err := s.table(stmt.table)
+	if err != nil {
+		return nil, err
+	}
+	tag := &fakeResult{tag: pgconn.CommandTag("CREATE INDEX")}
+	if !stmt.unique {
+		return tag, nil
+	}
+	name := stmt.name
+	if name == "" {
+		name = t.name + "_" + strings.Join(stmt.columns, "_") + "_idx"
+	}
+	for _, c := range t.constraints {
+		if c.name == name {
+			if stmt.ifNotExists {
+				return tag, nil
+			}
+			return nil, fakeError(errCodeDuplicateTable, "relation \"%s\" already exists", name)
+		}
+	}
+	t = t.clone()
+	t.constraints = append([]fakeConstraint(nil), t.constraints...)
+	if err := t.addConstraint(name, stmt.columns); err != nil {
+		return nil, err
+	}
+	for i, row := range t.rows {
+		if _, c := t.conflict(env.ci, row, i); c != nil {
+			err := t.duplicateKey(env.ci, c, row).(*pgconn.PgError)
+			err.Message = fmt.Sprintf("could not create unique index \"%s\"", name)
+			err.Detail = strings.Replace(err.Detail, "already exists", "is duplicated", 1)
+			return nil, err
+		}
+	}
+	s.tables[t.name] = t
+	return tag, nil
+}
+
+func (s *fakeStore) dropTable(stmt *fakeDropTable) (*fakeResult, error) {
+	if _, err := s.table(stmt.table); err != nil && !stmt.ifExists {
+		return nil, err
+	}
+	delete(s.tables, stmt.table)
+	return &fakeResult{tag: pgconn.CommandTag("DROP TABLE")}, nil
+}
+
+func (s *fakeStore) truncate(stmt *fakeTruncate) (*fakeResult, error) {
+	t, err := s.table(stmt.table)
+	if err != nil {
+		return nil, err
+	}
+	t = t.clone()
+	t.rows = nil
+	s.tables[t.name] = t
+	return &fakeResult{tag: pgconn.CommandTag("TRUNCATE TABLE")}, nil
+}
+
+func (s *fakeStore) insert(env *fakeEnv, stmt *fakeInsert) (*fakeResult, error) {
+	t, err := s.table(stmt.table)
+	if err != nil {
+		return nil, err
+	}
+	t = t.clone()
+	columns, err := t.columnIndexes(stmt.columns)
+	if err != nil {
+		return nil, err
+	}
+	var target *fakeConstraint
+	if stmt.conflict != nil && stmt.conflict.target != nil {
+		if target = t.constraintOn(stmt.conflict.target); target == nil {
+			return nil, fakeError("42P10", "there is no unique or exclusion constraint matching the ON CONFLICT specification")
+		}
+	}
+	var returned [][]interface{}
+	updated := make(map[int]bool)
+	for _, exprs := range stmt.rows {
+		if len(exprs) > len(columns) {
+			return nil, fakeError(errCodeSyntaxError, "INSERT has more expressions than target columns")
+		}
+		if len(exprs) < len(columns) {
+			return nil, fakeError(errCodeSyntaxError, "INSERT has more target columns than expressions")
+		}
+		values := make([]interface{}, len(exprs))
+		provided := make([]bool, len(exprs))
+		for i, e := range exprs {
+			if _, ok := e.(fakeDefault); ok {
+				continue
+			}
+			if values[i], err = env.eval(e); err != nil {
+				return nil, err
+			}
+			provided[i] = true
+		}
+		row, err := t.newRow(env, columns, values, provided)
+		if err != nil {
+			return nil, err
+		}
+		i, c := t.conflict(env.ci, row, -1)
+		if c == nil {
+			t.rows = append(t.rows, row)
+			returned = append(returned, row)
+			continue
+		}
+		if stmt.conflict == nil || target != nil && target.name != c.name {
+			return nil, t.duplicateKey(env.ci, c, row)
+		}
+		if !stmt.conflict.doUpdate {
+			continue
+		}
+		if updated[i] {
+			return nil, fakeError("21000", "ON CONFLICT DO UPDATE command cannot affect row a second time")
+		}
+		conflictEnv := env.with(fakeScope{name: t.name, table: t, row: t.rows[i]}, fakeScope{name: "excluded", qualified: true, table: t, row: row})
+		if ok, err := conflictEnv.test(stmt.conflict.where); err != nil || !ok {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		row, err = t.assign(conflictEnv, t.rows[i], stmt.conflict.sets)
+		if err != nil {
+			return nil, err
+		}
+		if _, c := t.conflict(env.ci, row, i); c != nil {
+			return nil, t.duplicateKey(env.ci, c, row)
+		}
+		t.rows[i] = row
+		updated[i] = true
+		returned = append(returned, row)
+	}
+	s.tables[t.name] = t
+	return env.returning(t, "", stmt.returning, returned, fmt.Sprintf("INSERT 0 %d", len(returned)))
+}
+
+// copyFrom inserts rows into the columns named columnNames of the table tableName, like COPY FROM does.
+func (s *fakeStore) copyFrom(tableName string, columnNames []string, rows [][]interface{}) (*fakeResult, error) {
+	t, err := s.table(tableName)
+	if err != nil {
+		return nil, err
+	}
+	t = t.clone()
+	columns, err := t.columnIndexes(columnNames)
+	if err != nil {
+		return nil, err
+	}
+	env := newFakeEnv(nil)
+	for _, values := range rows {
+		if len(values) != len(columns) {
+			return nil, fakeError("22P04", "expected %d values, got %d", len(columns), len(values))
+		}
+		args := make([]interface{}, len(values))
+		for i, v := range values {
+			args[i] = fakeArg(v)
+		}
+		row, err := t.newRow(env, columns, args, nil)
+		if err != nil {
+			return nil, err
+		}
+		if _, c := t.conflict(env.ci, row, -1); c != nil {
+			return nil, t.duplicateKey(env.ci, c, row)
+		}
+		t.rows = append(t.rows, row)
+	}
+	s.tables[t.name] = t
+	return &fakeResult{tag: pgconn.CommandTag(fmt.Sprintf("COPY %d", len(rows)))}, nil
+}
+
+func (s *fakeStore) selectRows(env *fakeEnv, stmt *fakeSelect) (*fakeResult, error) {
+	var t *fakeTable
+	rows := [][]interface{}{nil}
+	if stmt.table != "" {
+		var err error
+		if t, err = s.table(stmt.table); err != nil {
+			return nil, err
+		}
+		rows = t.rows
+	}
+	var matched [][]interface{}
+	for _, row := range rows {
+		ok, err := env.scope(t, stmt.alias, row).test(stmt.where)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, row)
+		}
+	}
+	outputs, err := env.outputs(t, stmt.items)
+	if err != nil {
+		return nil, err
+	}
+	if fakeAggregates(outputs) {
+		row, err := env.aggregate(t, stmt.alias, outputs, matched)
+		if err != nil {
+			return nil, err
+		}
+		res := &fakeResult{rows: [][]interface{}{row}}
+		for _, output := range outputs {
+			res.columns = append(res.columns, output.name)
+			res.dts = append(res.dts, output.dt)
+		}
+		return env.limit(res, stmt)
+	}
+	if err := env.sort(t, stmt.alias, outputs, stmt.orderBy, matched); err != nil {
+		return nil, err
+	}
+	res, err := env.project(t, stmt.alias, outputs, matched)
+	if err != nil {
+		return nil, err
+	}
+	return env.limit(res, stmt)
+}
+
+// limit applies the OFFSET and LIMIT of stmt to the rows of res, and tags it.
+func (env *fakeEnv) limit(res *fakeResult, stmt *fakeSelect) (*fakeResult, error) {
+	offset, err := env.count("OFFSET", stmt.offset)
+	if err != nil {
+		return nil, err
+	}
+	if offset >= 0 {
+		if offset > len(res.rows) {
+			offset = len(res.rows)
+		}
+		res.rows = res.rows[offset:]
+	}
+	limit, err := env.count("LIMIT", stmt.limit)
+	if err != nil {
+		return nil, err
+	}
+	if limit >= 0 && limit < len(res.rows) {
+		res.rows = res.rows[:limit]
+	}
+	res.tag = pgconn.CommandTag(fmt.Sprintf("SELECT %d", len(res.rows)))
+	return res, nil
+}
+
+// count evaluates the expression e of a LIMIT or an OFFSET clause, or returns -1 without e.
+func (env *fakeEnv) count(clause string, e fakeExpr) (int, error) {
+	if e == nil {
+		return -1, nil
+	}
+	v, err := env.eval(e)
+	if err != nil || v == nil {
+		return -1, err
+	}
+	n, ok := fakeNumber(v)
+	i, isInt := n.(int64)
+	if !ok || !isInt {
+		return 0, fakeError("42804", "argument of %s must be type bigint", clause)
+	}
+	if i < 0 {
+		return 0, fakeError("2201W", "%s must not be negative", clause)
+	}
+	return int(i), nil
+}
+
+// sort sorts rows by orderBy, whose items can also be the position or the name of an output.
+func (env *fakeEnv) sort(t *fakeTable, alias string, outputs []fakeOutput, orderBy []fakeOrderItem, rows [][]interface{}) error {
+	if len(orderBy) == 0 {
+		return nil
+	}
+	exprs := make([]fakeExpr, len(orderBy))
+	for i, item := range orderBy {
+		exprs[i] = item.expr
+		switch e := item.expr.(type) {
+		case fakeLiteral:
+			if n, ok := e.value.(int64); ok {
+				if n < 1 || int(n) > len(outputs) {
+					return fakeError("42P10", "ORDER BY position %d is not in select list", n)
+				}
+				exprs[i] = outputs[n-1].expr
+			}
+		case fakeColumnRef:
+			if e.table == "" && (t == nil || t.column(e.column) < 0) {
+				for _, output := range outputs {
+					if output.name == e.column {
+						exprs[i] = output.expr
+					}
+				}
+			}
+		}
+	}
+	keys := make([][]interface{}, len(rows))
+	for r, row := range rows {
+		rowEnv := env.scope(t, alias, row)
+		keys[r] = make([]interface{}, len(exprs))
+		for i, e := range exprs {
+			v, err := rowEnv.eval(e)
+			if err != nil {
+				return err
+			}
+			keys[r][i] = v
+		}
+	}
+	index := make([]int, len(rows))
+	for i := range index {
+		index[i] = i
+	}
+	var err error
+	sort.SliceStable(index, func(a, b int) bool {
+		for i, item := range orderBy {
+			ka, kb := keys[index[a]][i], keys[index[b]][i]
+			if ka == nil || kb == nil {
+				if ka == nil && kb == nil {
+					continue
+				}
+				return (ka == nil) == item.nullsFirst
+			}
+			c, cmpErr := fakeCompare(env.ci, ka, kb)
+			if cmpErr != nil {
+				err = cmpErr
+			}
+			if c != 0 {
+				return c < 0 != item.desc
+			}
+		}
+		return false
+	})
+	sorted := make([][]interface{}, len(rows))
+	for i, r := range index {
+		sorted[i] = rows[r]
+	}
+	copy(rows, sorted)
+	return err
+}
+
+func (s *fakeStore) update(env *fakeEnv, stmt *fakeUpdate) (*fakeResult, error) {
+	t, err := s.table(stmt.table)
+	if err != nil {
+		return nil, err
+	}
+	t = t.clone()
+	var updated []int
+	var returned [][]interface{}
+	for i, row := range t.rows {
+		rowEnv := env.scope(t, stmt.alias, row)
+		ok, err := rowEnv.test(stmt.where)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if row, err = t.assign(rowEnv, row, stmt.sets); err != nil {
+			return nil, err
+		}
+		updated = append(updated, i)
+		returned = append(returned, row)
+	}
+	for i, row := range returned {
+		t.rows[updated[i]] = row
+	}
+	for _, i := range updated {
+		if _, c := t.conflict(env.ci, t.rows[i], i); c != nil {
+			return nil, t.duplicateKey(env.ci, c, t.rows[i])
+		}
+	}
+	s.tables[t.name] = t
+	return env.returning(t, stmt.alias, stmt.returning, returned, fmt.Sprintf("UPDATE %d", len(returned)))
+}
+
+func (s *fakeStore) delete(env *fakeEnv, stmt *fakeDelete) (*fakeResult, error) {
+	t, err := s.table(stmt.table)
+	if err != nil {
+		return nil, err
+	}
+	t = t.clone()
+	var kept, returned [][]interface{}
+	for _, row := range t.rows {
+		ok, err := env.scope(t, stmt.alias, row).test(stmt.where)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			returned = append(returned, row)
+		} else {
+			kept = append(kept, row)
+		}
+	}
+	t.rows = kept
+	s.tables[t.name] = t
+	return env.returning(t, stmt.alias, stmt.returning, returned, fmt.Sprintf("DELETE %d", len(returned)))
+}
+
+// returning returns the rows of a statement, projected by the items of its RETURNING clause, tagged with tag.
+func (env *fakeEnv) returning(t *fakeTable, alias string, items []fakeSelectItem, rows [][]interface{}, tag string) (*fakeResult, error) {
+	res := &fakeResult{}
+	if items != nil {
+		outputs, err := env.outputs(t, items)
+		if err != nil {
+			return nil, err
+		}
+		if res, err = env.project(t, alias, outputs, rows); err != nil {
+			return nil, err
+		}
+	}
+	res.tag = pgconn.CommandTag(tag)
+	return res, nil
+}
+
+// outputs returns the outputs of the select items, with * expanded to the columns of t.
+func (env *fakeEnv) outputs(t *fakeTable, items []fakeSelectItem) ([]fakeOutput, error) {
+	var outputs []fakeOutput
+	for _, item := range items {
+		if item.star {
+			if t == nil {
+				return nil, fakeError(errCodeSyntaxError, "SELECT * with no tables specified is not valid")
+			}
+			for _, column := range t.columns {
+				outputs = append(outputs, fakeOutput{name: column.name, expr: fakeColumnRef{table: t.name, column: column.name}, dt: column.dt})
+			}
+			continue
+		}
+		output := fakeOutput{name: item.alias, expr: item.expr}
+		e := item.expr
+		if cast, ok := e.(fakeCast); ok {
+			output.dt, _ = dataTypeForName(env.ci, cast.typeName)
+			e = cast.expr
+		}
+		switch e := e.(type) {
+		case fakeColumnRef:
+			if output.name == "" {
+				output.name = e.column
+			}
+			if t != nil && output.dt == nil {
+				if i := t.column(e.column); i >= 0 {
+					output.dt = t.columns[i].dt
+				}
+			}
+		case fakeCall:
+			if output.name == "" {
+				output.name = e.name
+			}
+			if e.name == "count" {
+				output.dt, _ = env.ci.DataTypeForName("int8")
+			}
+		}
+		if output.name == "" {
+			output.name = "?column?"
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs, nil
+}
+
+func (env *fakeEnv) project(t *fakeTable, alias string, outputs []fakeOutput, rows [][]interface{}) (*fakeResult, error) {
+	res := &fakeResult{}
+	for _, output := range outputs {
+		res.columns = append(res.columns, output.name)
+		res.dts = append(res.dts, output.dt)
+	}
+	for _, row := range rows {
+		rowEnv := env.scope(t, alias, row)
+		values := make([]interface{}, len(outputs))
+		for i, output := range outputs {
+			v, err := rowEnv.eval(output.expr)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		res.rows = append(res.rows, values)
+	}
+	return res, nil
+}
+
+var fakeAggregateFuncs = map[string]bool{"count": true, "sum": true, "min": true, "max": true, "avg": true}
+
+// fakeAggregates tells whether the outputs are aggregates, like count(*).
+func fakeAggregates(outputs []fakeOutput) bool {
+	for _, output := range outputs {
+		if call, ok := output.expr.(fakeCall); ok && fakeAggregateFuncs[call.name] {
+			return true
+		}
+	}
+	return false
+}
+
+// aggregate returns the row of the aggregates outputs over rows.
+func (env *fakeEnv) aggregate(t *fakeTable, alias string, outputs []fakeOutput, rows [][]interface{}) ([]interface{}, error) {
+	row := make([]interface{}, len(outputs))
+	for i, output := range outputs {
+		call, ok := output.expr.(fakeCall)
+		if !ok || !fakeAggregateFuncs[call.name] {
+			return nil, fakeError("42803", "column \"%s\" must appear in the GROUP BY clause or be used in an aggregate function", output.name)
+		}
+		if call.star {
+			if call.name != "count" {
+				return nil, fakeError(errCodeSyntaxError, "%s(*) is not supported", call.name)
+			}
+			row[i] = int64(len(rows))
+			continue
+		}
+		if len(call.args) != 1 {
+			return nil, fakeError("42883", "function %s() does not exist", call.name)
+		}
+		var values []interface{}
+		for _, r := range rows {
+			v, err := env.scope(t, alias, r).eval(call.args[0])
+			if err != nil {
+				return nil, err
+			}
+			if v != nil {
+				values = append(values, v)
+			}
+		}
+		value, err := env.fold(call.name, values)
+		if err != nil {
+			return nil, err
+		}
+		row[i] = value
+	}
+	return row, nil
+}
+
+// fold returns the aggregate name of the values, which are not NULL.
+func (env *fakeEnv) fold(name string, values []interface{}) (interface{}, error) {
+	if name == "count" {
+		return int64(len(values)), nil
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	result := values[0]
+	for _, v := range values[1:] {
+		switch name {
+		case "min", "max":
+			c, err := fakeCompare(env.ci, v, result)
+			if err != nil {
+				return nil, err
+			}
+			if c < 0 == (name == "min") && c != 0 {
+				result = v
+			}
+		default:
+			var err error
+			if result, err = fakeArithmetic("+", result, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if name == "avg" {
+		return fakeArithmetic("/", fakeFloat(result), float64(len(values)))
+	}
+	return result, nil
+}
+
+func (t *fakeTable) clone() *fakeTable {
+	c := *t
+	c.rows = append([][]interface{}(nil), t.rows...)
+	return &c
+}
+
+// column returns the index of the column name, or -1.
+func (t *fakeTable) column(name string) int {
+	for i, column := range t.columns {
+		if column.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// columnIndexes returns the indexes of the columns named names, or of all the columns without names.
+func (t *fakeTable) columnIndexes(names []string) ([]int, error) {
+	if names == nil {
+		indexes := make([]int, len(t.columns))
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return indexes, nil
+	}
+	indexes := make([]int, len(names))
+	seen := make(map[int]bool)
+	for i, name := range names {
+		if indexes[i] = t.column(name); indexes[i] < 0 {
+			return nil, fakeError(ErrDBCodeColumnNotExists, "column \"%s\" of relation \"%s\" does not exist", name, t.name)
+		}
+		if seen[indexes[i]] {
+			return nil, fakeError("42701", "column \"%s\" specified more than once", name)
+		}
+		seen[indexes[i]] = true
+	}
+	return indexes, nil
+}
+
+func (t *fakeTable) addConstraint(name string, columns []string) error {
+	indexes, err := t.columnIndexes(columns)
+	if err != nil {
+		return err
+	}
+	t.constraints = append(t.constraints, fakeConstraint{name: name, columns: indexes})
+	return nil
+}
+
+// constraintOn returns the constraint over exactly the columns named columns, in any order.
+func (t *fakeTable) constraintOn(columns []string) *fakeConstraint {
+	for i, c := range t.constraints {
+		if len(c.columns) != len(columns) {
+			continue
+		}
+		matched := true
+		for _, name := range columns {
+			found := false
+			for _, index := range c.columns {
+				found = found || t.columns[index].name == name
+			}
+			matched = matched && found
+		}
+		if matched {
+			return &t.constraints[i]
+		}
+	}
+	return nil
+}
+
+// newRow returns a row with the values converted into the columns at the indexes columns, and the default values
+// of the other columns, or of the columns whose value is not provided.
+func (t *fakeTable) newRow(env *fakeEnv, columns []int, values []interface{}, provided []bool) ([]interface{}, error) {
+	row := make([]interface{}, len(t.columns))
+	set := make([]bool, len(t.columns))
+	for i, c := range columns {
+		if provided != nil && !provided[i] {
+			continue
+		}
+		v, err := fakeConvert(env.ci, t.columns[c].dt, values[i])
+		if err != nil {
+			return nil, err
+		}
+		row[c] = v
+		set[c] = true
+	}
+	for c := range t.columns {
+		if set[c] {
+			continue
+		}
+		v, err := t.defaultValue(env, c)
+		if err != nil {
+			return nil, err
+		}
+		row[c] = v
+	}
+	return row, t.checkNotNull(row)
+}
+
+// defaultValue returns the default value of the column at the index c, the next value of its sequence for a serial.
+func (t *fakeTable) defaultValue(env *fakeEnv, c int) (interface{}, error) {
+	column := t.columns[c]
+	switch {
+	case column.serial:
+		return fakeConvert(env.ci, column.dt, t.serials.next(c))
+	case column.def != nil:
+		v, err := env.eval(column.def)
+		if err != nil {
+			return nil, err
+		}
+		return fakeConvert(env.ci, column.dt, v)
+	}
+	return nil, nil
+}
+
+// assign returns a copy of row with the assignments of sets, evaluated in env.
+func (t *fakeTable) assign(env *fakeEnv, row []interface{}, sets []fakeAssignment) ([]interface{}, error) {
+	updated := append([]interface{}(nil), row...)
+	for _, set := range sets {
+		c := t.column(set.column)
+		if c < 0 {
+			return nil, fakeError(ErrDBCodeColumnNotExists, "column \"%s\" of relation \"%s\" does not exist", set.column, t.name)
+		}
+		var v interface{}
+		var err error
+		if _, ok := set.value.(fakeDefault); ok {
+			v, err = t.defaultValue(env, c)
+		} else if v, err = env.eval(set.value); err == nil {
+			v, err = fakeConvert(env.ci, t.columns[c].dt, v)
+		}
+		if err != nil {
+			return nil, err
+		}
+		updated[c] = v
+	}
+	return updated, t.checkNotNull(updated)
+}
+
+func (t *fakeTable) checkNotNull(row []interface{}) error {
+	for i, column := range t.columns {
+		if column.notNull && row[i] == nil {
+			return &pgconn.PgError{
+				Severity:   "ERROR",
+				Code:       errCodeNotNullViolation,
+				Message:    fmt.Sprintf("null value in column \"%s\" of relation \"%s\" violates not-null constraint", column.name, t.name),
+				TableName:  t.name,
+				ColumnName: column.name,
+			}
+		}
+	}
+	return nil
+}
+
+// conflict returns the index of the row, other than the row at the index skip, which has the same key as row for a
+// constraint, and the constraint. Keys with NULL never conflict.
+func (t *fakeTable) conflict(ci *pgtype.ConnInfo, row []interface{}, skip int) (int, *fakeConstraint) {
+	for k := range t.constraints {
+		c := &t.constraints[k]
+		key, ok := t.key(ci, c, row)
+		if !ok {
+			continue
+		}
+		for i, other := range t.rows {
+			if i == skip {
+				continue
+			}
+			if otherKey, ok := t.key(ci, c, other); ok && otherKey == key {
+				return i, c
+			}
+		}
+	}
+	return -1, nil
+}
+
+func (t *fakeTable) key(ci *pgtype.ConnInfo, c *fakeConstraint, row []interface{}) (string, bool) {
+	texts := make([]string, len(c.columns))
+	for i, index := range c.columns {
+		text := goldenText(ci, row[index])
+		if text == nil {
+			return "", false
+		}
+		texts[i] = *text
+	}
+	return strings.Join(texts, ", "), true
+}
+
+func (t *fakeTable) duplicateKey(ci *pgtype.ConnInfo, c *fakeConstraint, row []interface{}) error {
+	names := make([]string, len(c.columns))
+	for i, index := range c.columns {
+		names[i] = t.columns[index].name
+	}
+	key, _ := t.key(ci, c, row)
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           ErrDBCodeDuplicateKey,
+		Message:        fmt.Sprintf("duplicate key value violates unique constraint \"%s\"", c.name),
+		Detail:         fmt.Sprintf("Key (%s)=(%s) already exists.", strings.Join(names, ", "), key),
+		TableName:      t.name,
+		ConstraintName: c.name,
+	}
+}
+
+// mockRows returns the result as MockRows, typed by the types of the columns it was read from.
+func (res *fakeResult) mockRows() *MockRows {
+	columns := make([]*MockColumn, len(res.columns))
+	for i, name := range res.columns {
+		columns[i] = NewMockColumn(name)
+		if res.dts[i] != nil {
+			columns[i].WithOID(res.dts[i].OID)
+		}
+	}
+	mr := NewMockRowsWithColumns(columns...).AddCommandTag(res.tag)
+	for _, row := range res.rows {
+		values := make([]interface{}, len(row))
+		for i, v := range row {
+			values[i] = fakeRowValue(v)
+		}
+		mr.AddRow(values...)
+	}
+	return mr
+}
+
+// fakeRowValue returns the Go value of a pgtype value v, like the string of *pgtype.Varchar, which Values returns
+// the way pgx does. Values whose Go value is their pgtype type, like *pgtype.Numeric, are kept.
+func fakeRowValue(v interface{}) interface{} {
+	value, ok := v.(pgtype.Value)
+	if !ok {
+		return v
+	}
+	get := value.Get()
+	if get == nil {
+		return v
+	}
+	if _, ok := reflect.New(reflect.TypeOf(get)).Interface().(pgtype.Value); ok {
+		return v
+	}
+	return get
+}
+
+func newFakeEnv(args []interface{}) *fakeEnv {
+	env := &fakeEnv{ci: pgtype.NewConnInfo(), now: time.Now()}
+	for _, arg := range args {
+		env.args = append(env.args, fakeArg(arg))
+	}
+	return env
+}
+
+// fakeArg returns the value of the argument v: the value of a driver.Valuer, the value a pointer points to, or nil
+// for NULL.
+func fakeArg(v interface{}) interface{} {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case pgtype.Value:
+		if value.Get() == nil {
+			return nil
+		}
+		return v
+	case driver.Valuer:
+		if dv, err := value.Value(); err == nil {
+			return fakeArg(dv)
+		}
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		return fakeArg(rv.Elem().Interface())
+	}
+	return v
+}
+
+// with returns env reading the columns of scopes.
+func (env *fakeEnv) with(scopes ...fakeScope) *fakeEnv {
+	scoped := *env
+	scoped.scopes = scopes
+	return &scoped
+}
+
+// scope returns env reading the columns of row of t, or env without t.
+func (env *fakeEnv) scope(t *fakeTable, alias string, row []interface{}) *fakeEnv {
+	if t == nil {
+		return env
+	}
+	return env.with(fakeScope{name: t.name, alias: alias, table: t, row: row})
+}
+
+// test tells whether the condition e is true. A NULL condition is not.
+func (env *fakeEnv) test(e fakeExpr) (bool, error) {
+	if e == nil {
+		return true, nil
+	}
+	v, err := env.eval(e)
+	if err != nil {
+		return false, err
+	}
+	return fakeBool(v, "WHERE")
+}
+
+func fakeBool(v interface{}, clause string) (bool, error) {
+	if v == nil {
+		return false, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fakeError("42804", "argument of %s must be type boolean", clause)
+	}
+	return b, nil
+}
+
+func (env *fakeEnv) eval(e fakeExpr) (interface{}, error) {
+	switch e := e.(type) {
+	case fakeLiteral:
+		return e.value, nil
+	case fakeParam:
+		return env.args[e.index], nil
+	case fakeColumnRef:
+		return env.column(e)
+	case fakeNot:
+		v, err := env.eval(e.expr)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		b, err := fakeBool(v, "NOT")
+		return !b, err
+	case fakeNeg:
+		v, err := env.eval(e.expr)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		return fakeArithmetic("-", int64(0), v)
+	case fakeIsNull:
+		v, err := env.eval(e.expr)
+		return (v == nil) != e.not, err
+	case fakeIn:
+		return env.in(e)
+	case fakeLike:
+		return env.like(e)
+	case fakeCall:
+		return env.call(e)
+	case fakeCast:
+		v, err := env.eval(e.expr)
+		if err != nil {
+			return nil, err
+		}
+		dt, ok := dataTypeForName(env.ci, e.typeName)
+		if !ok {
+			return nil, fakeError("42704", "type \"%s\" does not exist", e.typeName)
+		}
+		return fakeConvert(env.ci, dt, v)
+	case fakeBinary:
+		return env.binary(e)
+	}
+	return nil, fakeError(errCodeSyntaxError, "DEFAULT is not allowed in this context")
+}
+
+func (env *fakeEnv) column(ref fakeColumnRef) (interface{}, error) {
+	for _, scope := range env.scopes {
+		if ref.table == "" && scope.qualified || ref.table != "" && ref.table != scope.name && ref.table != scope.alias {
+			continue
+		}
+		if i := scope.table.column(ref.column); i >= 0 {
+			return scope.row[i], nil
+		}
+	}
+	if ref.table != "" {
+		return nil, fakeError(ErrDBCodeColumnNotExists, "column %s.%s does not exist", ref.table, ref.column)
+	}
+	return nil, fakeError(ErrDBCodeColumnNotExists, "column \"%s\" does not exist", ref.column)
+}
+
+func (env *fakeEnv) in(e fakeIn) (interface{}, error) {
+	v, err := env.eval(e.expr)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	null := false
+	for _, item := range e.list {
+		w, err := env.eval(item)
+		if err != nil {
+			return nil, err
+		}
+		if w == nil {
+			null = true
+			continue
+		}
+		c, err := fakeCompare(env.ci, v, w)
+		if err != nil {
+			return nil, err
+		}
+		if c == 0 {
+			return !e.not, nil
+		}
+	}
+	if null {
+		return nil, nil
+	}
+	return e.not, nil
+}
+
+func (env *fakeEnv) like(e fakeLike) (interface{}, error) {
+	v, err := env.eval(e.expr)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := env.eval(e.pattern)
+	if err != nil || v == nil || pattern == nil {
+		return nil, err
+	}
+	var b strings.Builder
+	b.WriteString("(?s")
+	if e.ilike {
+		b.WriteString("i")
+	}
+	b.WriteString(")^")
+	escaped := false
+	for _, r := range *goldenText(env.ci, pattern) {
+		switch {
+		case escaped:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			b.WriteString(".*")
+		case r == '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fakeError("22025", "invalid pattern")
+	}
+	return re.MatchString(*goldenText(env.ci, v)) != e.not, nil
+}
+
+func (env *fakeEnv) call(e fakeCall) (interface{}, error) {
+	if fakeAggregateFuncs[e.name] {
+		return nil, fakeError("42803", "aggregate functions are not allowed here")
+	}
+	args := make([]interface{}, len(e.args))
+	for i, arg := range e.args {
+		v, err := env.eval(arg)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	switch {
+	case e.name == "now" && len(args) == 0:
+		return env.now, nil
+	case e.name == "coalesce" && len(args) > 0:
+		for _, arg := range args {
+			if arg != nil {
+				return arg, nil
+			}
+		}
+		return nil, nil
+	case (e.name == "lower" || e.name == "upper" || e.name == "length") && len(args) == 1:
+		if args[0] == nil {
+			return nil, nil
+		}
+		text := *goldenText(env.ci, args[0])
+		switch e.name {
+		case "lower":
+			return strings.ToLower(text), nil
+		case "upper":
+			return strings.ToUpper(text), nil
+		}
+		return int64(len([]rune(text))), nil
+	}
+	return nil, fakeError("42883", "function %s() does not exist", e.name)
+}
+
+func (env *fakeEnv) binary(e fakeBinary) (interface{}, error) {
+	l, err := env.eval(e.left)
+	if err != nil {
+		return nil, err
+	}
+	r, err := env.eval(e.right)
+	if err != nil {
+		return nil, err
+	}
+	switch e.op {
+	case "and", "or":
+		lb, err := fakeBool(l, strings.ToUpper(e.op))
+		if err != nil {
+			return nil, err
+		}
+		rb, err := fakeBool(r, strings.ToUpper(e.op))
+		if err != nil {
+			return nil, err
+		}
+		// The result is decided by one operand which is not NULL, false for AND and true for OR.
+		decisive := e.op == "or"
+		if l != nil && lb == decisive || r != nil && rb == decisive {
+			return decisive, nil
+		}
+		if l == nil || r == nil {
+			return nil, nil
+		}
+		return !decisive, nil
+	}
+	if l == nil || r == nil {
+		return nil, nil
+	}
+	switch e.op {
+	case "=", "<>", "<", "<=", ">", ">=":
+		c, err := fakeCompare(env.ci, l, r)
+		if err != nil {
+			return nil, err
+		}
+		switch e.op {
+		case "=":
+			return c == 0, nil
+		case "<>":
+			return c != 0, nil
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		}
+		return c >= 0, nil
+	case "||":
+		return *goldenText(env.ci, l) + *goldenText(env.ci, r), nil
+	}
+	return fakeArithmetic(e.op, l, r)
+}
+
+// fakeConvert converts the value v into the type dt of a column. The value is a Go value when pgtype maps it back to
+// dt, like int32 for int4, or the pgtype value otherwise.
+func fakeConvert(ci *pgtype.ConnInfo, dt *pgtype.DataType, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	value := pgtype.NewValue(dt.Value)
+	if err := value.Set(v); err == nil {
+		return goValue(ci, dt, value), nil
+	}
+	text := goldenText(ci, v)
+	if text == nil {
+		return nil, nil
+	}
+	converted, err := fixtureValue(ci, dt, *text)
+	if err != nil {
+		return nil, fakeError(ErrDBCodeInvalidInputSyntax, "invalid input syntax for type %s: \"%s\"", dt.Name, *text)
+	}
+	return converted, nil
+}
+
+// fakeComparable returns the Go value of a pgtype value v, when it has a comparable one.
+func fakeComparable(v interface{}) interface{} {
+	if value, ok := v.(pgtype.Value); ok {
+		switch get := value.Get().(type) {
+		case string, bool, time.Time, int16, int32, int64, float32, float64:
+			return get
+		}
+	}
+	return v
+}
+
+// fakeCompare compares a and b, which are not NULL, as numbers, times, booleans, or their text. A string compared
+// with another type is converted into it first, like an untyped literal.
+func fakeCompare(ci *pgtype.ConnInfo, a interface{}, b interface{}) (int, error) {
+	a, b = fakeComparable(a), fakeComparable(b)
+	_, aString := a.(string)
+	_, bString := b.(string)
+	if aString != bString {
+		other, text := a, b
+		if aString {
+			other, text = b, a
+		}
+		if dt, ok := ci.DataTypeForValue(other); ok {
+			converted, err := fakeConvert(ci, dt, text)
+			if err != nil {
+				return 0, err
+			}
+			converted = fakeComparable(converted)
+			if aString {
+				a = converted
+			} else {
+				b = converted
+			}
+		}
+	}
+	if an, ok := fakeNumber(a); ok {
+		if bn, ok := fakeNumber(b); ok {
+			ai, aInt := an.(int64)
+			bi, bInt := bn.(int64)
+			if aInt && bInt {
+				switch {
+				case ai < bi:
+					return -1, nil
+				case ai > bi:
+					return 1, nil
+				}
+				return 0, nil
+			}
+			return fakeSign(fakeFloat(an) - fakeFloat(bn)), nil
+		}
+	}
+	if at, ok := a.(time.Time); ok {
+		if bt, ok := b.(time.Time); ok {
+			switch {
+			case at.Before(bt):
+				return -1, nil
+			case at.After(bt):
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	if ab, ok := a.(bool); ok {
+		if bb, ok := b.(bool); ok {
+			if ab == bb {
+				return 0, nil
+			}
+			if bb {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	return strings.Compare(*goldenText(ci, a), *goldenText(ci, b)), nil
+}
+
+func fakeSign(f float64) int {
+	switch {
+	case f < 0:
+		return -1
+	case f > 0:
+		return 1
+	}
+	return 0
+}
+
+// fakeNumber returns the number v as an int64 or a float64.
+func fakeNumber(v interface{}) (interface{}, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	if value, ok := v.(*pgtype.Numeric); ok {
+		var f float64
+		if value.AssignTo(&f) == nil {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+func fakeFloat(v interface{}) float64 {
+	switch n, _ := fakeNumber(v); n := n.(type) {
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+func fakeArithmetic(op string, l interface{}, r interface{}) (interface{}, error) {
+	ln, lok := fakeNumber(fakeComparable(l))
+	rn, rok := fakeNumber(fakeComparable(r))
+	if !lok || !rok {
+		return nil, fakeError("42883", "operator does not exist: %T %s %T", l, op, r)
+	}
+	li, lInt := ln.(int64)
+	ri, rInt := rn.(int64)
+	if lInt && rInt {
+		switch op {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		}
+		if ri == 0 {
+			return nil, fakeError("22012", "division by zero")
+		}
+		if op == "/" {
+			return li / ri, nil
+		}
+		return li % ri, nil
+	}
+	lf, rf := fakeFloat(ln), fakeFloat(rn)
+	switch op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	}
+	if rf == 0 {
+		return nil, fakeError("22012", "division by zero")
+	}
+	if op == "/" {
+		return lf / rf, nil
+	}
+	return nil, fakeError("42883", "operator does not exist: double precision %% double precision")
+}
@@ -0,0 +1,1076 @@
+package pgxpoolgo
+
+import (
+	"fmt"
+	"github.com/jackc/pgconn"
+	"strconv"
+	"strings"
+)
+
+const (
+	errCodeSyntaxError          = "42601"
+	errCodeUndefinedTable       = "42P01"
+	errCodeDuplicateTable       = "42P07"
+	errCodeNotNullViolation     = "23502"
+	errCodeSerializationFailure = "40001"
+	errCodeFeatureNotSupported  = "0A000"
+)
+
+type fakeTokenKind int
+
+const (
+	fakeTokenEOF fakeTokenKind = iota
+	fakeTokenIdent
+	fakeTokenQuotedIdent
+	fakeTokenNumber
+	fakeTokenString
+	fakeTokenParam
+	fakeTokenSymbol
+)
+
+type fakeToken struct {
+	kind fakeTokenKind
+	text string
+}
+
+// fakeLex splits sql into tokens. Identifiers and keywords are lowercased, unless they are quoted.
+func fakeLex(sql string) ([]fakeToken, error) {
+	var tokens []fakeToken
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case isIdentStart(c):
+			j := i
+			for j < len(sql) && (isIdentStart(sql[j]) || sql[j] >= '0' && sql[j] <= '9' || sql[j] == '$') {
+				j++
+			}
+			tokens = append(tokens, fakeToken{fakeTokenIdent, strings.ToLower(sql[i:j])})
+			i = j
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(sql) && sql[i+1] >= '0' && sql[i+1] <= '9':
+			j := i
+			for j < len(sql) && (sql[j] >= '0' && sql[j] <= '9' || sql[j] == '.' || sql[j] == 'e' || sql[j] == 'E') {
+				j++
+			}
+			tokens = append(tokens, fakeToken{fakeTokenNumber, sql[i:j]})
+			i = j
+		case c == '$':
+			j := i + 1
+			for j < len(sql) && sql[j] >= '0' && sql[j] <= '9' {
+				j++
+			}
+			if j == i+1 {
+				return nil, fakeSyntaxError("$")
+			}
+			tokens = append(tokens, fakeToken{fakeTokenParam, sql[i+1 : j]})
+			i = j
+		case c == '\'' || c == '"':
+			var b strings.Builder
+			j := i + 1
+			for {
+				if j >= len(sql) {
+					return nil, &pgconn.PgError{Severity: "ERROR", Code: errCodeSyntaxError, Message: "unterminated quoted string"}
+				}
+				if sql[j] == c {
+					if j+1 < len(sql) && sql[j+1] == c {
+						b.WriteByte(c)
+						j += 2
+						continue
+					}
+					break
+				}
+				b.WriteByte(sql[j])
+				j++
+			}
+			kind := fakeTokenString
+			if c == '"' {
+				kind = fakeTokenQuotedIdent
+			}
+			tokens = append(tokens, fakeToken{kind, b.String()})
+			i = j + 1
+		default:
+			symbol := string(c)
+			if i+1 < len(sql) {
+				switch two := sql[i : i+2]; two {
+				case "<>", "!=", "<=", ">=", "::", "||":
+					symbol = two
+				}
+			}
+			if !strings.Contains("(),;*=<>!+-/.%:|[]", symbol[:1]) {
+				return nil, fakeSyntaxError(symbol)
+			}
+			tokens = append(tokens, fakeToken{fakeTokenSymbol, symbol})
+			i += len(symbol)
+		}
+	}
+	return append(tokens, fakeToken{kind: fakeTokenEOF}), nil
+}
+
+func isIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= 0x80
+}
+
+func fakeSyntaxError(near string) error {
+	if near == "" {
+		return &pgconn.PgError{Severity: "ERROR", Code: errCodeSyntaxError, Message: "syntax error at end of input"}
+	}
+	return &pgconn.PgError{Severity: "ERROR", Code: errCodeSyntaxError, Message: fmt.Sprintf("syntax error at or near \"%s\"", near)}
+}
+
+// The statements of the SQL subset of FakePool.
+
+type fakeStatement interface{}
+
+type fakeCreateTable struct {
+	table       string
+	ifNotExists bool
+	columns     []fakeColumnDef
+	primaryKey  []string
+	uniques     [][]string
+}
+
+type fakeColumnDef struct {
+	name     string
+	typeName string
+	notNull  bool
+	primary  bool
+	unique   bool
+	serial   bool
+	def      fakeExpr
+}
+
+type fakeCreateIndex struct {
+	name        string
+	table       string
+	unique      bool
+	ifNotExists bool
+	columns     []string
+}
+
+type fakeDropTable struct {
+	table    string
+	ifExists bool
+}
+
+type fakeTruncate struct {
+	table string
+}
+
+type fakeInsert struct {
+	table     string
+	columns   []string
+	rows      [][]fakeExpr
+	conflict  *fakeOnConflict
+	returning []fakeSelectItem
+}
+
+type fakeOnConflict struct {
+	target   []string
+	doUpdate bool
+	sets     []fakeAssignment
+	where    fakeExpr
+}
+
+type fakeAssignment struct {
+	column string
+	value  fakeExpr
+}
+
+type fakeSelect struct {
+	items   []fakeSelectItem
+	table   string
+	alias   string
+	where   fakeExpr
+	orderBy []fakeOrderItem
+	limit   fakeExpr
+	offset  fakeExpr
+}
+
+type fakeSelectItem struct {
+	star  bool
+	expr  fakeExpr
+	alias string
+}
+
+type fakeOrderItem struct {
+	expr       fakeExpr
+	desc       bool
+	nullsFirst bool
+}
+
+type fakeUpdate struct {
+	table     string
+	alias     string
+	sets      []fakeAssignment
+	where     fakeExpr
+	returning []fakeSelectItem
+}
+
+type fakeDelete struct {
+	table     string
+	alias     string
+	where     fakeExpr
+	returning []fakeSelectItem
+}
+
+// The expressions of the SQL subset of FakePool.
+
+type fakeExpr interface{}
+
+type fakeLiteral struct {
+	value interface{}
+}
+
+type fakeParam struct {
+	index int
+}
+
+type fakeColumnRef struct {
+	table  string
+	column string
+}
+
+type fakeDefault struct{}
+
+type fakeBinary struct {
+	op    string
+	left  fakeExpr
+	right fakeExpr
+}
+
+type fakeNot struct {
+	expr fakeExpr
+}
+
+type fakeNeg struct {
+	expr fakeExpr
+}
+
+type fakeIsNull struct {
+	expr fakeExpr
+	not  bool
+}
+
+type fakeIn struct {
+	expr fakeExpr
+	list []fakeExpr
+	not  bool
+}
+
+type fakeLike struct {
+	expr    fakeExpr
+	pattern fakeExpr
+	not     bool
+	ilike   bool
+}
+
+type fakeCall struct {
+	name string
+	args []fakeExpr
+	star bool
+}
+
+type fakeCast struct {
+	expr     fakeExpr
+	typeName string
+}
+
+type fakeParser struct {
+	tokens []fakeToken
+	pos    int
+}
+
+// fakeParse parses a statement of the SQL subset of FakePool.
+func fakeParse(sql string) (fakeStatement, error) {
+	tokens, err := fakeLex(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &fakeParser{tokens: tokens}
+	var stmt fakeStatement
+	switch p.peek().text {
+	case "create":
+		if p.tokens[p.pos+1].text == "table" {
+			stmt, err = p.createTable()
+		} else {
+			stmt, err = p.createIndex()
+		}
+	case "drop":
+		stmt, err = p.dropTable()
+	case "truncate":
+		stmt, err = p.truncate()
+	case "insert":
+		stmt, err = p.insert()
+	case "select":
+		stmt, err = p.selectStmt()
+	case "update":
+		stmt, err = p.update()
+	case "delete":
+		stmt, err = p.delete()
+	default:
+		if p.peek().kind == fakeTokenEOF {
+			return nil, fakeSyntaxError("")
+		}
+		if fakeUnsupported[p.peek().text] {
+			return nil, &pgconn.PgError{Severity: "ERROR", Code: errCodeFeatureNotSupported, Message: fmt.Sprintf("FakePool does not support %s statements", strings.ToUpper(p.peek().text))}
+		}
+		return nil, p.unexpected()
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.accept(";")
+	if p.peek().kind != fakeTokenEOF {
+		return nil, p.unexpected()
+	}
+	return stmt, nil
+}
+
+// fakeUnsupported are the statements FakePool does not support.
+var fakeUnsupported = map[string]bool{
+	"alter": true, "begin": true, "commit": true, "copy": true, "grant": true, "merge": true, "rollback": true,
+	"savepoint": true, "set": true, "show": true, "start": true, "values": true, "with": true,
+}
+
+func (p *fakeParser) peek() fakeToken {
+	return p.tokens[p.pos]
+}
+
+func (p *fakeParser) next() fakeToken {
+	t := p.tokens[p.pos]
+	if t.kind != fakeTokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// is tells whether the next token is one of the keywords or symbols words.
+func (p *fakeParser) is(words ...string) bool {
+	t := p.peek()
+	if t.kind != fakeTokenIdent && t.kind != fakeTokenSymbol {
+		return false
+	}
+	for _, w := range words {
+		if t.text == w {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *fakeParser) accept(words ...string) bool {
+	if p.pos+len(words) > len(p.tokens) {
+		return false
+	}
+	for i, w := range words {
+		t := p.tokens[p.pos+i]
+		if (t.kind != fakeTokenIdent && t.kind != fakeTokenSymbol) || t.text != w {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+func (p *fakeParser) expect(words ...string) error {
+	if !p.accept(words...) {
+		return p.unexpected()
+	}
+	return nil
+}
+
+func (p *fakeParser) unexpected() error {
+	t := p.peek()
+	if t.kind == fakeTokenEOF {
+		return fakeSyntaxError("")
+	}
+	return fakeSyntaxError(t.text)
+}
+
+func (p *fakeParser) ident() (string, error) {
+	t := p.peek()
+	if t.kind != fakeTokenIdent && t.kind != fakeTokenQuotedIdent {
+		return "", p.unexpected()
+	}
+	p.next()
+	return t.text, nil
+}
+
+// tableName parses a table name, dropping its schema.
+func (p *fakeParser) tableName() (string, error) {
+	name, err := p.ident()
+	if err != nil {
+		return "", err
+	}
+	if p.accept(".") {
+		return p.ident()
+	}
+	return name, nil
+}
+
+func (p *fakeParser) identList() ([]string, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var names []string
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if !p.accept(",") {
+			break
+		}
+	}
+	return names, p.expect(")")
+}
+
+func (p *fakeParser) createTable() (fakeStatement, error) {
+	p.next()
+	if err := p.expect("table"); err != nil {
+		return nil, err
+	}
+	stmt := &fakeCreateTable{ifNotExists: p.accept("if", "not", "exists")}
+	var err error
+	if stmt.table, err = p.tableName(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("primary", "key"):
+			if stmt.primaryKey, err = p.identList(); err != nil {
+				return nil, err
+			}
+		case p.accept("unique"):
+			unique, err := p.identList()
+			if err != nil {
+				return nil, err
+			}
+			stmt.uniques = append(stmt.uniques, unique)
+		default:
+			column, err := p.columnDef()
+			if err != nil {
+				return nil, err
+			}
+			stmt.columns = append(stmt.columns, column)
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+	return stmt, p.expect(")")
+}
+
+func (p *fakeParser) createIndex() (fakeStatement, error) {
+	p.next()
+	stmt := &fakeCreateIndex{unique: p.accept("unique")}
+	if err := p.expect("index"); err != nil {
+		return nil, err
+	}
+	stmt.ifNotExists = p.accept("if", "not", "exists")
+	var err error
+	if !p.is("on") {
+		if stmt.name, err = p.ident(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("on"); err != nil {
+		return nil, err
+	}
+	if stmt.table, err = p.tableName(); err != nil {
+		return nil, err
+	}
+	if p.accept("using") {
+		if _, err := p.ident(); err != nil {
+			return nil, err
+		}
+	}
+	stmt.columns, err = p.identList()
+	return stmt, err
+}
+
+func (p *fakeParser) columnDef() (fakeColumnDef, error) {
+	var column fakeColumnDef
+	var err error
+	if column.name, err = p.ident(); err != nil {
+		return column, err
+	}
+	if column.typeName, err = p.typeName(); err != nil {
+		return column, err
+	}
+	switch column.typeName {
+	case "serial", "bigserial", "smallserial":
+		column.serial = true
+		column.notNull = true
+	}
+	for {
+		switch {
+		case p.accept("primary", "key"):
+			column.primary = true
+			column.notNull = true
+		case p.accept("unique"):
+			column.unique = true
+		case p.accept("not", "null"):
+			column.notNull = true
+		case p.accept("null"):
+		case p.accept("default"):
+			if column.def, err = p.expr(); err != nil {
+				return column, err
+			}
+		case p.accept("references"):
+			if _, err := p.tableName(); err != nil {
+				return column, err
+			}
+			if p.is("(") {
+				if _, err := p.identList(); err != nil {
+					return column, err
+				}
+			}
+		default:
+			return column, nil
+		}
+	}
+}
+
+// typeName parses a type name, like "bigint", "varchar(255)", "timestamp with time zone" or "text[]".
+func (p *fakeParser) typeName() (string, error) {
+	name, err := p.ident()
+	if err != nil {
+		return "", err
+	}
+	for _, suffix := range [][]string{{"precision"}, {"varying"}, {"with", "time", "zone"}, {"without", "time", "zone"}} {
+		if p.accept(suffix...) {
+			name += " " + strings.Join(suffix, " ")
+		}
+	}
+	if p.accept("(") {
+		for !p.accept(")") {
+			if p.next().kind == fakeTokenEOF {
+				return "", fakeSyntaxError("")
+			}
+		}
+	}
+	if p.accept("[") {
+		if err := p.expect("]"); err != nil {
+			return "", err
+		}
+		name += "[]"
+	}
+	return name, nil
+}
+
+func (p *fakeParser) dropTable() (fakeStatement, error) {
+	p.next()
+	if err := p.expect("table"); err != nil {
+		return nil, err
+	}
+	stmt := &fakeDropTable{ifExists: p.accept("if", "exists")}
+	var err error
+	stmt.table, err = p.tableName()
+	return stmt, err
+}
+
+func (p *fakeParser) truncate() (fakeStatement, error) {
+	p.next()
+	p.accept("table")
+	table, err := p.tableName()
+	return &fakeTruncate{table: table}, err
+}
+
+func (p *fakeParser) insert() (fakeStatement, error) {
+	p.next()
+	if err := p.expect("into"); err != nil {
+		return nil, err
+	}
+	stmt := &fakeInsert{}
+	var err error
+	if stmt.table, err = p.tableName(); err != nil {
+		return nil, err
+	}
+	if p.is("(") {
+		if stmt.columns, err = p.identList(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("values"); err != nil {
+		return nil, err
+	}
+	for {
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		var row []fakeExpr
+		for {
+			var e fakeExpr
+			if p.accept("default") {
+				e = fakeDefault{}
+			} else if e, err = p.expr(); err != nil {
+				return nil, err
+			}
+			row = append(row, e)
+			if !p.accept(",") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		stmt.rows = append(stmt.rows, row)
+		if !p.accept(",") {
+			break
+		}
+	}
+	if p.accept("on", "conflict") {
+		stmt.conflict = &fakeOnConflict{}
+		if p.is("(") {
+			if stmt.conflict.target, err = p.identList(); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect("do"); err != nil {
+			return nil, err
+		}
+		if !p.accept("nothing") {
+			if err := p.expect("update", "set"); err != nil {
+				return nil, err
+			}
+			if stmt.conflict.target == nil {
+				return nil, &pgconn.PgError{Severity: "ERROR", Code: errCodeSyntaxError, Message: "ON CONFLICT DO UPDATE requires inference specification or constraint name"}
+			}
+			stmt.conflict.doUpdate = true
+			if stmt.conflict.sets, err = p.assignments(); err != nil {
+				return nil, err
+			}
+			if p.accept("where") {
+				if stmt.conflict.where, err = p.expr(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	stmt.returning, err = p.returning()
+	return stmt, err
+}
+
+func (p *fakeParser) assignments() ([]fakeAssignment, error) {
+	var sets []fakeAssignment
+	for {
+		column, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if p.accept(".") {
+			if column, err = p.ident(); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		var value fakeExpr
+		if p.accept("default") {
+			value = fakeDefault{}
+		} else if value, err = p.expr(); err != nil {
+			return nil, err
+		}
+		sets = append(sets, fakeAssignment{column: column, value: value})
+		if !p.accept(",") {
+			return sets, nil
+		}
+	}
+}
+
+func (p *fakeParser) returning() ([]fakeSelectItem, error) {
+	if !p.accept("returning") {
+		return nil, nil
+	}
+	return p.selectItems()
+}
+
+func (p *fakeParser) selectItems() ([]fakeSelectItem, error) {
+	var items []fakeSelectItem
+	for {
+		var item fakeSelectItem
+		if p.accept("*") {
+			item.star = true
+		} else {
+			var err error
+			if item.expr, err = p.expr(); err != nil {
+				return nil, err
+			}
+			if p.accept("as") {
+				if item.alias, err = p.ident(); err != nil {
+					return nil, err
+				}
+			} else if t := p.peek(); t.kind == fakeTokenQuotedIdent || t.kind == fakeTokenIdent && !fakeReserved[t.text] {
+				item.alias = p.next().text
+			}
+		}
+		items = append(items, item)
+		if !p.accept(",") {
+			return items, nil
+		}
+	}
+}
+
+// fakeReserved are the keywords which end a select item or a table reference.
+var fakeReserved = map[string]bool{
+	"from": true, "where": true, "order": true, "limit": true, "offset": true, "returning": true, "set": true,
+	"on": true, "group": true, "for": true,
+}
+
+func (p *fakeParser) alias() string {
+	if p.accept("as") || p.peek().kind == fakeTokenIdent && !fakeReserved[p.peek().text] || p.peek().kind == fakeTokenQuotedIdent {
+		return p.next().text
+	}
+	return ""
+}
+
+func (p *fakeParser) selectStmt() (fakeStatement, error) {
+	p.next()
+	stmt := &fakeSelect{}
+	var err error
+	if stmt.items, err = p.selectItems(); err != nil {
+		return nil, err
+	}
+	if p.accept("from") {
+		if stmt.table, err = p.tableName(); err != nil {
+			return nil, err
+		}
+		stmt.alias = p.alias()
+	}
+	if p.accept("where") {
+		if stmt.where, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("order", "by") {
+		for {
+			item := fakeOrderItem{}
+			if item.expr, err = p.expr(); err != nil {
+				return nil, err
+			}
+			if p.accept("desc") {
+				item.desc = true
+			} else {
+				p.accept("asc")
+			}
+			item.nullsFirst = item.desc
+			if p.accept("nulls", "first") {
+				item.nullsFirst = true
+			} else if p.accept("nulls", "last") {
+				item.nullsFirst = false
+			}
+			stmt.orderBy = append(stmt.orderBy, item)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	for {
+		switch {
+		case p.accept("limit"):
+			if stmt.limit, err = p.expr(); err != nil {
+				return nil, err
+			}
+		case p.accept("offset"):
+			if stmt.offset, err = p.expr(); err != nil {
+				return nil, err
+			}
+		default:
+			return stmt, nil
+		}
+	}
+}
+
+func (p *fakeParser) update() (fakeStatement, error) {
+	p.next()
+	stmt := &fakeUpdate{}
+	var err error
+	if stmt.table, err = p.tableName(); err != nil {
+		return nil, err
+	}
+	stmt.alias = p.alias()
+	if err := p.expect("set"); err != nil {
+		return nil, err
+	}
+	if stmt.sets, err = p.assignments(); err != nil {
+		return nil, err
+	}
+	if p.accept("where") {
+		if stmt.where, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	stmt.returning, err = p.returning()
+	return stmt, err
+}
+
+func (p *fakeParser) delete() (fakeStatement, error) {
+	p.next()
+	if err := p.expect("from"); err != nil {
+		return nil, err
+	}
+	stmt := &fakeDelete{}
+	var err error
+	if stmt.table, err = p.tableName(); err != nil {
+		return nil, err
+	}
+	stmt.alias = p.alias()
+	if p.accept("where") {
+		if stmt.where, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	stmt.returning, err = p.returning()
+	return stmt, err
+}
+
+// expr parses an expression, from the lowest precedence: OR, AND, NOT, comparisons, additions, multiplications,
+// and unary operators.
+func (p *fakeParser) expr() (fakeExpr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = fakeBinary{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *fakeParser) and() (fakeExpr, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and") {
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = fakeBinary{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *fakeParser) not() (fakeExpr, error) {
+	if p.accept("not") {
+		e, err := p.not()
+		return fakeNot{expr: e}, err
+	}
+	return p.comparison()
+}
+
+func (p *fakeParser) comparison() (fakeExpr, error) {
+	left, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.is("=", "<>", "!=", "<", "<=", ">", ">="):
+		op := p.next().text
+		if op == "!=" {
+			op = "<>"
+		}
+		right, err := p.additive()
+		return fakeBinary{op: op, left: left, right: right}, err
+	case p.accept("is"):
+		not := p.accept("not")
+		if err := p.expect("null"); err != nil {
+			return nil, err
+		}
+		return fakeIsNull{expr: left, not: not}, nil
+	}
+	not := p.accept("not")
+	switch {
+	case p.accept("in"):
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		in := fakeIn{expr: left, not: not}
+		for {
+			e, err := p.additive()
+			if err != nil {
+				return nil, err
+			}
+			in.list = append(in.list, e)
+			if !p.accept(",") {
+				break
+			}
+		}
+		return in, p.expect(")")
+	case p.is("like", "ilike"):
+		ilike := p.next().text == "ilike"
+		pattern, err := p.additive()
+		return fakeLike{expr: left, pattern: pattern, not: not, ilike: ilike}, err
+	case p.accept("between"):
+		low, err := p.additive()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("and"); err != nil {
+			return nil, err
+		}
+		high, err := p.additive()
+		if err != nil {
+			return nil, err
+		}
+		var between fakeExpr = fakeBinary{op: "and", left: fakeBinary{op: ">=", left: left, right: low}, right: fakeBinary{op: "<=", left: left, right: high}}
+		if not {
+			between = fakeNot{expr: between}
+		}
+		return between, nil
+	}
+	if not {
+		return nil, p.unexpected()
+	}
+	return left, nil
+}
+
+func (p *fakeParser) additive() (fakeExpr, error) {
+	left, err := p.multiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("+", "-", "||") {
+		op := p.next().text
+		right, err := p.multiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = fakeBinary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *fakeParser) multiplicative() (fakeExpr, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("*", "/", "%") {
+		op := p.next().text
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = fakeBinary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *fakeParser) unary() (fakeExpr, error) {
+	if p.accept("-") {
+		e, err := p.unary()
+		return fakeNeg{expr: e}, err
+	}
+	e, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("::") {
+		typeName, err := p.typeName()
+		if err != nil {
+			return nil, err
+		}
+		e = fakeCast{expr: e, typeName: typeName}
+	}
+	return e, nil
+}
+
+func (p *fakeParser) primary() (fakeExpr, error) {
+	t := p.next()
+	switch t.kind {
+	case fakeTokenNumber:
+		if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return fakeLiteral{value: i}, nil
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fakeSyntaxError(t.text)
+		}
+		return fakeLiteral{value: f}, nil
+	case fakeTokenString:
+		return fakeLiteral{value: t.text}, nil
+	case fakeTokenParam:
+		n, _ := strconv.Atoi(t.text)
+		if n < 1 {
+			return nil, fakeSyntaxError("$" + t.text)
+		}
+		return fakeParam{index: n - 1}, nil
+	case fakeTokenSymbol:
+		if t.text == "(" {
+			e, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			return e, p.expect(")")
+		}
+	case fakeTokenIdent, fakeTokenQuotedIdent:
+		if t.kind == fakeTokenIdent {
+			if t.text == "select" {
+				return nil, &pgconn.PgError{Severity: "ERROR", Code: errCodeFeatureNotSupported, Message: "FakePool does not support subqueries"}
+			}
+			if fakeReserved[t.text] {
+				break
+			}
+			switch t.text {
+			case "null":
+				return fakeLiteral{}, nil
+			case "true":
+				return fakeLiteral{value: true}, nil
+			case "false":
+				return fakeLiteral{value: false}, nil
+			case "current_timestamp":
+				return fakeCall{name: "now"}, nil
+			}
+			if p.is("(") {
+				return p.call(t.text)
+			}
+		}
+		if p.accept(".") {
+			column, err := p.ident()
+			return fakeColumnRef{table: t.text, column: column}, err
+		}
+		return fakeColumnRef{column: t.text}, nil
+	}
+	p.pos--
+	return nil, p.unexpected()
+}
+
+func (p *fakeParser) call(name string) (fakeExpr, error) {
+	p.next()
+	call := fakeCall{name: name}
+	if p.accept("*") {
+		call.star = true
+		return call, p.expect(")")
+	}
+	if p.accept(")") {
+		return call, nil
+	}
+	for {
+		arg, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+		if !p.accept(",") {
+			break
+		}
+	}
+	return call, p.expect(")")
+}
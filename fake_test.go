@@ -0,0 +1,337 @@
+package pgxpoolgo_test
+
+import (
+	"context"
+	"errors"
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type fakeUser struct {
+	ID        int64
+	Name      string
+	Age       *int
+	CreatedAt time.Time
+}
+
+type fakeQuerier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+func fakeUsersPool(t *testing.T) *pgxpoolgo.FakePool {
+	pool := pgxpoolgo.NewFakePool()
+	_, err := pool.Exec(context.Background(), `CREATE TABLE users (
+		id bigserial PRIMARY KEY,
+		name text NOT NULL UNIQUE,
+		age int,
+		visits int NOT NULL DEFAULT 0,
+		created_at timestamptz NOT NULL DEFAULT now()
+	)`)
+	assert.Nil(t, err)
+	return pool
+}
+
+func fakeCreateUser(ctx context.Context, q fakeQuerier, name string, age interface{}) (int64, error) {
+	var id int64
+	err := q.QueryRow(ctx, `INSERT INTO users (name, age) VALUES ($1, $2) RETURNING id`, name, age).Scan(&id)
+	return id, err
+}
+
+func fakeFindUsers(ctx context.Context, q fakeQuerier, minAge int) ([]fakeUser, error) {
+	rows, err := q.Query(ctx, `SELECT id, name, age, created_at FROM users WHERE age >= $1 OR age IS NULL ORDER BY age DESC NULLS LAST, name LIMIT 10`, minAge)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var users []fakeUser
+	for rows.Next() {
+		var user fakeUser
+		if err := rows.Scan(&user.ID, &user.Name, &user.Age, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func TestFakePool_OK(t *testing.T) {
+	ctx := context.Background()
+	pool := fakeUsersPool(t)
+
+	id, err := fakeCreateUser(ctx, pool, "johndoe", 30)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), id)
+	id, err = fakeCreateUser(ctx, pool, "janedoe", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), id)
+	_, err = fakeCreateUser(ctx, pool, "kid", 12)
+	assert.Nil(t, err)
+
+	users, err := fakeFindUsers(ctx, pool, 18)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(users))
+	assert.Equal(t, "johndoe", users[0].Name)
+	assert.Equal(t, 30, *users[0].Age)
+	assert.Equal(t, false, users[0].CreatedAt.IsZero())
+	assert.Equal(t, "janedoe", users[1].Name)
+	assert.Nil(t, users[1].Age)
+
+	tag, err := pool.Exec(ctx, `UPDATE users SET visits = visits + 1, age = $1 WHERE name LIKE 'j%' AND id <> $2`, 40, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "UPDATE 1", tag.String())
+
+	var visits, age int
+	err = pool.QueryRow(ctx, `SELECT visits, age FROM users WHERE id = $1`, 2).Scan(&visits, &age)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, visits)
+	assert.Equal(t, 40, age)
+
+	var count int64
+	assert.Nil(t, pool.QueryRow(ctx, `SELECT count(*) FROM users`).Scan(&count))
+	assert.Equal(t, int64(3), count)
+
+	var deleted string
+	err = pool.QueryRow(ctx, `DELETE FROM users WHERE age < 18 RETURNING name`).Scan(&deleted)
+	assert.Nil(t, err)
+	assert.Equal(t, "kid", deleted)
+
+	err = pool.QueryRow(ctx, `SELECT name FROM users WHERE id = $1`, 3).Scan(&deleted)
+	assert.Equal(t, pgx.ErrNoRows, err)
+}
+
+func TestFakePool_DuplicateKey(t *testing.T) {
+	ctx := context.Background()
+	pool := fakeUsersPool(t)
+
+	_, err := fakeCreateUser(ctx, pool, "johndoe", 30)
+	assert.Nil(t, err)
+	_, err = fakeCreateUser(ctx, pool, "johndoe", 31)
+	assert.NotNil(t, err)
+	assert.Equal(t, true, pgxpoolgo.ErrDB(err).IsDuplicateKey())
+
+	var pgErr *pgconn.PgError
+	assert.Equal(t, true, errors.As(err, &pgErr))
+	assert.Equal(t, "users_name_key", pgErr.ConstraintName)
+	assert.Equal(t, "Key (name)=(johndoe) already exists.", pgErr.Detail)
+
+	_, err = pool.Exec(ctx, `INSERT INTO users (name, age) VALUES ('a', 1), ('b', 'old')`)
+	assert.Equal(t, true, pgxpoolgo.ErrDB(err).IsInvalidInputSyntax())
+	_, err = pool.Exec(ctx, `SELECT nickname FROM users`)
+	assert.Equal(t, true, pgxpoolgo.ErrDB(err).IsColumnNotExists())
+
+	var count int64
+	assert.Nil(t, pool.QueryRow(ctx, `SELECT count(*) FROM users`).Scan(&count))
+	assert.Equal(t, int64(1), count)
+}
+
+func TestFakePool_OnConflict(t *testing.T) {
+	ctx := context.Background()
+	pool := fakeUsersPool(t)
+
+	_, err := fakeCreateUser(ctx, pool, "johndoe", 30)
+	assert.Nil(t, err)
+
+	tag, err := pool.Exec(ctx, `INSERT INTO users (name, age) VALUES ($1, $2) ON CONFLICT DO NOTHING`, "johndoe", 31)
+	assert.Nil(t, err)
+	assert.Equal(t, "INSERT 0 0", tag.String())
+
+	var id int64
+	var age int
+	err = pool.QueryRow(ctx, `INSERT INTO users (name, age) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET age = EXCLUDED.age, visits = users.visits + 1
+		RETURNING id, age`, "johndoe", 32).Scan(&id, &age)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), id)
+	assert.Equal(t, 32, age)
+
+	_, err = pool.Exec(ctx, `INSERT INTO users (name) VALUES ($1) ON CONFLICT (age) DO NOTHING`, "janedoe")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "no unique or exclusion constraint matching the ON CONFLICT specification")
+}
+
+func TestFakePool_Transaction(t *testing.T) {
+	ctx := context.Background()
+	pool := fakeUsersPool(t)
+
+	err := pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if _, err := fakeCreateUser(ctx, tx, "johndoe", 30); err != nil {
+			return err
+		}
+		_, err := fakeCreateUser(ctx, tx, "johndoe", 31)
+		return err
+	})
+	assert.Equal(t, true, pgxpoolgo.ErrDB(err).IsDuplicateKey())
+	users, err := fakeFindUsers(ctx, pool, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(users))
+
+	tx, err := pool.Begin(ctx)
+	assert.Nil(t, err)
+	_, err = fakeCreateUser(ctx, tx, "janedoe", 25)
+	assert.Nil(t, err)
+	users, err = fakeFindUsers(ctx, tx, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(users))
+	users, err = fakeFindUsers(ctx, pool, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(users))
+	assert.Nil(t, tx.Commit(ctx))
+	assert.Equal(t, pgx.ErrTxClosed, tx.Rollback(ctx))
+
+	users, err = fakeFindUsers(ctx, pool, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(users))
+}
+
+func TestFakePool_TransactionConflict(t *testing.T) {
+	ctx := context.Background()
+	pool := fakeUsersPool(t)
+
+	tx1, err := pool.Begin(ctx)
+	assert.Nil(t, err)
+	tx2, err := pool.Begin(ctx)
+	assert.Nil(t, err)
+
+	_, err = fakeCreateUser(ctx, tx1, "johndoe", 30)
+	assert.Nil(t, err)
+	_, err = fakeCreateUser(ctx, tx2, "janedoe", 25)
+	assert.Nil(t, err)
+	assert.Nil(t, tx1.Commit(ctx))
+	assert.Nil(t, tx2.Commit(ctx))
+
+	tx1, err = pool.Begin(ctx)
+	assert.Nil(t, err)
+	tx2, err = pool.Begin(ctx)
+	assert.Nil(t, err)
+	_, err = tx1.Exec(ctx, `UPDATE users SET age = 31 WHERE name = $1`, "johndoe")
+	assert.Nil(t, err)
+	_, err = tx2.Exec(ctx, `UPDATE users SET age = 32 WHERE name = $1`, "johndoe")
+	assert.Nil(t, err)
+	_, err = tx2.Exec(ctx, `UPDATE users SET age = 26 WHERE name = $1`, "janedoe")
+	assert.Nil(t, err)
+	assert.Nil(t, tx1.Commit(ctx))
+	err = tx2.Commit(ctx)
+	assert.NotNil(t, err)
+	assert.Equal(t, "40001", pgxpoolgo.ErrDB(err).DBCode)
+
+	tx1, err = pool.Begin(ctx)
+	assert.Nil(t, err)
+	tx2, err = pool.Begin(ctx)
+	assert.Nil(t, err)
+	_, err = fakeCreateUser(ctx, tx1, "kid", 10)
+	assert.Nil(t, err)
+	_, err = fakeCreateUser(ctx, tx2, "kid", 11)
+	assert.Nil(t, err)
+	assert.Nil(t, tx1.Commit(ctx))
+	err = tx2.Commit(ctx)
+	assert.Equal(t, true, pgxpoolgo.ErrDB(err).IsDuplicateKey())
+
+	tx, err := pool.Begin(ctx)
+	assert.Nil(t, err)
+	_, err = tx.Exec(ctx, `SELECT missing FROM users`)
+	assert.NotNil(t, err)
+	_, err = tx.Exec(ctx, `SELECT 1`)
+	assert.Contains(t, err.Error(), "current transaction is aborted")
+	assert.Equal(t, pgx.ErrTxCommitRollback, tx.Commit(ctx))
+
+	users, err := fakeFindUsers(ctx, pool, 0)
+	assert.Nil(t, err)
+	if assert.Equal(t, 3, len(users)) {
+		assert.Equal(t, "johndoe", users[0].Name)
+		assert.Equal(t, 31, *users[0].Age)
+		assert.Equal(t, "janedoe", users[1].Name)
+		assert.Equal(t, 25, *users[1].Age)
+		assert.Equal(t, "kid", users[2].Name)
+		assert.Equal(t, 10, *users[2].Age)
+	}
+}
+
+func TestFakePool_SerialNotRolledBack(t *testing.T) {
+	ctx := context.Background()
+	pool := fakeUsersPool(t)
+
+	_, err := pool.Exec(ctx, `INSERT INTO users (id, name) VALUES (2, 'johndoe')`)
+	assert.Nil(t, err)
+	_, err = fakeCreateUser(ctx, pool, "janedoe", 25)
+	assert.Nil(t, err)
+	_, err = fakeCreateUser(ctx, pool, "kid", 10)
+	assert.Equal(t, true, pgxpoolgo.ErrDB(err).IsDuplicateKey())
+
+	tx, err := pool.Begin(ctx)
+	assert.Nil(t, err)
+	_, err = fakeCreateUser(ctx, tx, "rolledback", 40)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Rollback(ctx))
+
+	id, err := fakeCreateUser(ctx, pool, "kid", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), id)
+}
+
+func TestFakePool_Savepoint(t *testing.T) {
+	ctx := context.Background()
+	pool := fakeUsersPool(t)
+
+	err := pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if _, err := fakeCreateUser(ctx, tx, "johndoe", 30); err != nil {
+			return err
+		}
+		_ = tx.BeginFunc(ctx, func(sp pgx.Tx) error {
+			if _, err := fakeCreateUser(ctx, sp, "janedoe", 25); err != nil {
+				return err
+			}
+			return errors.New("rolled back")
+		})
+		return nil
+	})
+	assert.Nil(t, err)
+
+	users, err := fakeFindUsers(ctx, pool, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(users))
+	assert.Equal(t, "johndoe", users[0].Name)
+}
+
+func TestFakePool_SendBatch(t *testing.T) {
+	ctx := context.Background()
+	pool := fakeUsersPool(t)
+
+	b := &pgx.Batch{}
+	b.Queue(`INSERT INTO users (name, age) VALUES ($1, $2)`, "johndoe", 30)
+	b.Queue(`SELECT name FROM users`)
+	br := pool.SendBatch(ctx, b)
+	tag, err := br.Exec()
+	assert.Nil(t, err)
+	assert.Equal(t, "INSERT 0 1", tag.String())
+	var name string
+	assert.Nil(t, br.QueryRow().Scan(&name))
+	assert.Equal(t, "johndoe", name)
+	assert.Nil(t, br.Close())
+
+	b = &pgx.Batch{}
+	b.Queue(`INSERT INTO users (name, age) VALUES ($1, $2)`, "janedoe", 25)
+	b.Queue(`INSERT INTO users (name, age) VALUES ($1, $2)`, "johndoe", 31)
+	br = pool.SendBatch(ctx, b)
+	_, err = br.Exec()
+	assert.Nil(t, err)
+	_, err = br.Exec()
+	assert.Equal(t, true, pgxpoolgo.ErrDB(err).IsDuplicateKey())
+	assert.Equal(t, true, pgxpoolgo.ErrDB(br.Close()).IsDuplicateKey())
+
+	n, err := pool.CopyFrom(ctx, pgx.Identifier{"users"}, []string{"name", "age"}, pgx.CopyFromRows([][]interface{}{
+		{"janedoe", 25},
+		{"kid", nil},
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), n)
+
+	users, err := fakeFindUsers(ctx, pool, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(users))
+	assert.Equal(t, []string{"johndoe", "janedoe", "kid"}, []string{users[0].Name, users[1].Name, users[2].Name})
+}
@@ -0,0 +1,387 @@
+package pgxpoolgo
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgtype"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FixtureTypes maps the columns of a fixture to PostgreSQL type names, like "int8", "numeric", "timestamptz",
+// "uuid", "jsonb" or "text[]", to convert their values into.
+type FixtureTypes map[string]string
+
+// fixtureDir is the directory fixtures are looked up in when they are not found at their path.
+const fixtureDir = "testdata"
+
+// fixture is the content of a fixture file: the names of its columns, in the order of the file, and its records.
+type fixture struct {
+	columns []string
+	records []map[string]interface{}
+}
+
+// LoadMockRows mocks pgx.Rows with the records of the fixture file at path, which is read as CSV, JSON or YAML by
+// its extension, .csv, .json, .yaml or .yml. A relative path which does not exist is looked up in testdata/, so
+// LoadMockRows("users.csv", nil) loads testdata/users.csv. The columns of the values listed in types are converted
+// to their type, and the others keep the values of the file.
+func LoadMockRows(path string, types FixtureTypes) (*MockRows, error) {
+	f, err := loadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	return f.mockRows(types)
+}
+
+// LoadMockRow mocks pgx.Row with the single record of the fixture file at path, loaded the way LoadMockRows does.
+func LoadMockRow(path string, types FixtureTypes) (*MockRow, error) {
+	f, err := loadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.records) != 1 {
+		return nil, fmt.Errorf("expected a single record in fixture %s, got %d", path, len(f.records))
+	}
+	columns, values, err := f.values(types)
+	if err != nil {
+		return nil, err
+	}
+	return NewMockRowWithColumns(columns...).AddRow(values[0]...), nil
+}
+
+// NewMockRowsFromCSV mocks pgx.Rows with the records of CSV read from r. The header names the columns, and empty
+// fields are NULL. Without a type in types, the values of a column are strings.
+func NewMockRowsFromCSV(r io.Reader, types FixtureTypes) (*MockRows, error) {
+	f, err := readCSVFixture(r)
+	if err != nil {
+		return nil, err
+	}
+	return f.mockRows(types)
+}
+
+// NewMockRowsFromJSON mocks pgx.Rows with the records of a JSON array of objects read from r. The keys of the
+// objects name the columns, in the order they first appear. Without a type in types, numbers are int64, or float64
+// when they are not integers.
+func NewMockRowsFromJSON(r io.Reader, types FixtureTypes) (*MockRows, error) {
+	f, err := readJSONFixture(r)
+	if err != nil {
+		return nil, err
+	}
+	return f.mockRows(types)
+}
+
+// NewMockRowsFromYAML mocks pgx.Rows with the records of a YAML sequence of mappings read from r. The keys of the
+// mappings name the columns, in the order they first appear. Without a type in types, the values are decoded by
+// their YAML tag.
+func NewMockRowsFromYAML(r io.Reader, types FixtureTypes) (*MockRows, error) {
+	f, err := readYAMLFixture(r)
+	if err != nil {
+		return nil, err
+	}
+	return f.mockRows(types)
+}
+
+// openFixture opens the file at path, or in testdata/ when a relative path does not exist.
+func openFixture(path string) (*os.File, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) && !filepath.IsAbs(path) {
+		file, err = os.Open(filepath.Join(fixtureDir, path))
+	}
+	return file, err
+}
+
+func loadFixture(path string) (*fixture, error) {
+	file, err := openFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var f *fixture
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		f, err = readCSVFixture(file)
+	case ".json":
+		f, err = readJSONFixture(file)
+	case ".yaml", ".yml":
+		f, err = readYAMLFixture(file)
+	default:
+		return nil, fmt.Errorf("unsupported fixture format '%s' of %s", ext, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't read fixture %s: %w", path, err)
+	}
+	return f, nil
+}
+
+func readCSVFixture(r io.Reader) (*fixture, error) {
+	lines, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("missing CSV header")
+	}
+	f := &fixture{columns: lines[0]}
+	for _, line := range lines[1:] {
+		record := make(map[string]interface{}, len(line))
+		for i, field := range line {
+			if field != "" {
+				record[f.columns[i]] = field
+			}
+		}
+		f.records = append(f.records, record)
+	}
+	return f, nil
+}
+
+func readJSONFixture(r io.Reader) (*fixture, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	f := &fixture{}
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok == json.Delim('{') {
+		return f, f.readJSONRecord(dec)
+	}
+	if tok != json.Delim('[') {
+		return nil, fmt.Errorf("expected an array of objects, got %v", tok)
+	}
+	for dec.More() {
+		if tok, err = dec.Token(); err != nil {
+			return nil, err
+		}
+		if tok != json.Delim('{') {
+			return nil, fmt.Errorf("expected an object, got %v", tok)
+		}
+		if err := f.readJSONRecord(dec); err != nil {
+			return nil, err
+		}
+	}
+	_, err = dec.Token()
+	return f, err
+}
+
+// readJSONRecord reads the keys and values of an object, after its opening brace, keeping the order of its keys.
+func (f *fixture) readJSONRecord(dec *json.Decoder) error {
+	record := make(map[string]interface{})
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		f.set(record, tok.(string), value)
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	f.records = append(f.records, record)
+	return nil
+}
+
+func readYAMLFixture(r io.Reader) (*fixture, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	f := &fixture{}
+	if len(doc.Content) == 0 {
+		return f, nil
+	}
+	nodes := []*yaml.Node{doc.Content[0]}
+	if doc.Content[0].Kind == yaml.SequenceNode {
+		nodes = doc.Content[0].Content
+	}
+	for _, node := range nodes {
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("line %d: expected a mapping", node.Line)
+		}
+		record := make(map[string]interface{})
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			var value interface{}
+			if err := node.Content[i+1].Decode(&value); err != nil {
+				return nil, err
+			}
+			f.set(record, node.Content[i].Value, value)
+		}
+		f.records = append(f.records, record)
+	}
+	return f, nil
+}
+
+// set sets the value of the column of record, adding the column to the fixture when it is new.
+func (f *fixture) set(record map[string]interface{}, column string, value interface{}) {
+	if _, ok := record[column]; !ok {
+		known := false
+		for _, c := range f.columns {
+			known = known || c == column
+		}
+		if !known {
+			f.columns = append(f.columns, column)
+		}
+	}
+	record[column] = value
+}
+
+func (f *fixture) mockRows(types FixtureTypes) (*MockRows, error) {
+	columns, values, err := f.values(types)
+	if err != nil {
+		return nil, err
+	}
+	mr := NewMockRowsWithColumns(columns...)
+	for _, row := range values {
+		mr.AddRow(row...)
+	}
+	return mr, nil
+}
+
+// values returns the columns of the fixture, typed by types, and the values of its records, converted to the types.
+func (f *fixture) values(types FixtureTypes) ([]*MockColumn, [][]interface{}, error) {
+	ci := pgtype.NewConnInfo()
+	columns := make([]*MockColumn, len(f.columns))
+	dts := make([]*pgtype.DataType, len(f.columns))
+	for i, name := range f.columns {
+		columns[i] = NewMockColumn(name)
+		typeName, ok := types[name]
+		if !ok {
+			continue
+		}
+		if dts[i], ok = dataTypeForName(ci, typeName); !ok {
+			return nil, nil, fmt.Errorf("unknown type %s of column %s", typeName, name)
+		}
+		columns[i].WithOID(dts[i].OID)
+	}
+	values := make([][]interface{}, len(f.records))
+	for r, record := range f.records {
+		values[r] = make([]interface{}, len(f.columns))
+		for i, name := range f.columns {
+			value, err := fixtureValue(ci, dts[i], record[name])
+			if err != nil {
+				return nil, nil, fmt.Errorf("can't convert value of column '%s' in record %d: %w", name, r, err)
+			}
+			values[r][i] = value
+		}
+	}
+	return columns, values, nil
+}
+
+// fixtureValue converts the value v of a fixture to the data type dt, by decoding its text. The value is the Go
+// value of the decoded type when pgtype maps it back to dt, like int64 for int8, or the pgtype value otherwise, like
+// *pgtype.Numeric, which scans into any destination pgtype supports. Without dt, JSON numbers become int64 or float64.
+func fixtureValue(ci *pgtype.ConnInfo, dt *pgtype.DataType, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if dt == nil {
+		switch v := v.(type) {
+		case json.Number:
+			if i, err := v.Int64(); err == nil {
+				return i, nil
+			}
+			return v.Float64()
+		case int:
+			return int64(v), nil
+		}
+		return v, nil
+	}
+	text, err := fixtureText(v, strings.HasPrefix(dt.Name, "_"))
+	if err != nil {
+		return nil, err
+	}
+	switch dt.OID {
+	case pgtype.TimestampOID, pgtype.TimestamptzOID:
+		if len(text) > 10 && text[10] == 'T' {
+			text = text[:10] + " " + text[11:]
+		}
+	}
+	value := pgtype.NewValue(dt.Value)
+	decoder, ok := value.(pgtype.TextDecoder)
+	if !ok {
+		return nil, fmt.Errorf("type %s can't be decoded from text", dt.Name)
+	}
+	if err := decoder.DecodeText(ci, []byte(text)); err != nil {
+		return nil, err
+	}
+	return goValue(ci, dt, value), nil
+}
+
+// goValue returns the Go value of value, of the data type dt, when pgtype maps it back to dt, or value otherwise.
+func goValue(ci *pgtype.ConnInfo, dt *pgtype.DataType, value pgtype.Value) interface{} {
+	if get := value.Get(); get != nil {
+		if getDT, ok := ci.DataTypeForValue(get); ok && getDT.OID == dt.OID {
+			return get
+		}
+	}
+	return value
+}
+
+// fixtureText returns the text of the value v of a fixture. Under an array type, lists are PostgreSQL array
+// literals, and other lists and objects are JSON.
+func fixtureText(v interface{}, array bool) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int, int64, float64:
+		return fmt.Sprint(v), nil
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05.999999999Z07:00"), nil
+	case []interface{}:
+		if array {
+			return arrayLiteral(v)
+		}
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// arrayLiteral returns the PostgreSQL array literal of the elements, like {"a","b"}, with nested lists as nested
+// arrays and nil as NULL.
+func arrayLiteral(elements []interface{}) (string, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, element := range elements {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		switch element := element.(type) {
+		case nil:
+			b.WriteString("NULL")
+		case []interface{}:
+			text, err := arrayLiteral(element)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(text)
+		default:
+			text, err := fixtureText(element, false)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(`"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`)
+		}
+	}
+	b.WriteByte('}')
+	return b.String(), nil
+}
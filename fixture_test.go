@@ -0,0 +1,154 @@
+package pgxpoolgo_test
+
+import (
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fixtureColumns(rows pgx.Rows) []string {
+	var columns []string
+	for _, def := range rows.FieldDescriptions() {
+		columns = append(columns, string(def.Name))
+	}
+	return columns
+}
+
+func TestLoadMockRows_CSV(t *testing.T) {
+	mockRows, err := pgxpoolgo.LoadMockRows("users.csv", pgxpoolgo.FixtureTypes{
+		"id":         "bigint",
+		"balance":    "numeric",
+		"created_at": "timestamptz",
+		"token":      "uuid",
+		"tags":       "text[]",
+		"profile":    "jsonb",
+	})
+	assert.Nil(t, err)
+	rows := mockRows.Compose()
+	assert.Equal(t, []string{"id", "name", "balance", "created_at", "token", "tags", "profile"}, fixtureColumns(rows))
+	assert.Equal(t, uint32(pgtype.NumericOID), rows.FieldDescriptions()[2].DataTypeOID)
+
+	var id int64
+	var name string
+	var balance float64
+	var createdAt time.Time
+	var token string
+	var tags []string
+	var profile map[string]string
+
+	assert.Equal(t, true, rows.Next())
+	assert.Nil(t, rows.Scan(&id, &name, &balance, &createdAt, &token, &tags, &profile))
+	assert.Equal(t, int64(1), id)
+	assert.Equal(t, "johndoe", name)
+	assert.Equal(t, 12.5, balance)
+	assert.Equal(t, true, createdAt.Equal(time.Date(2022, 10, 1, 8, 30, 0, 0, time.UTC)))
+	assert.Equal(t, "f47ac10b-58cc-4372-a567-0e02b2c3d479", token)
+	assert.Equal(t, []string{"admin", "staff"}, tags)
+	assert.Equal(t, map[string]string{"theme": "dark"}, profile)
+
+	var nullBalance *float64
+	var nullProfile map[string]string
+
+	assert.Equal(t, true, rows.Next())
+	assert.Nil(t, rows.Scan(&id, &name, &nullBalance, &createdAt, &token, &tags, &nullProfile))
+	assert.Nil(t, nullBalance)
+	assert.Nil(t, nullProfile)
+	assert.Equal(t, []string{}, tags)
+	assert.Equal(t, false, rows.Next())
+	assert.Nil(t, rows.Err())
+}
+
+func TestLoadMockRows_JSON(t *testing.T) {
+	mockRows, err := pgxpoolgo.LoadMockRows("testdata/users.json", pgxpoolgo.FixtureTypes{"tags": "text[]", "profile": "jsonb"})
+	assert.Nil(t, err)
+	rows := mockRows.Compose()
+	assert.Equal(t, []string{"id", "name", "score", "active", "tags", "profile", "email"}, fixtureColumns(rows))
+
+	assert.Equal(t, true, rows.Next())
+	values, err := rows.Values()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), values[0])
+	assert.Equal(t, 7.5, values[2])
+	assert.Equal(t, true, values[3])
+	assert.Nil(t, values[6])
+
+	var tags []string
+	var profile map[string]string
+	assert.Nil(t, rows.Scan(nil, nil, nil, nil, &tags, &profile, nil))
+	assert.Equal(t, []string{"admin", "staff"}, tags)
+	assert.Equal(t, map[string]string{"theme": "dark"}, profile)
+
+	var email string
+	assert.Equal(t, true, rows.Next())
+	assert.Nil(t, rows.Scan(nil, nil, nil, nil, nil, nil, &email))
+	assert.Equal(t, "janedoe@email.com", email)
+}
+
+func TestLoadMockRows_YAML(t *testing.T) {
+	mockRows, err := pgxpoolgo.LoadMockRows("users.yaml", pgxpoolgo.FixtureTypes{"id": "int4", "tags": "text[]"})
+	assert.Nil(t, err)
+	rows := mockRows.Compose()
+	assert.Equal(t, []string{"id", "name", "active", "tags"}, fixtureColumns(rows))
+	assert.Equal(t, uint32(pgtype.Int4OID), rows.FieldDescriptions()[0].DataTypeOID)
+
+	var id int32
+	var name string
+	var active bool
+	var tags []string
+
+	assert.Equal(t, true, rows.Next())
+	assert.Nil(t, rows.Scan(&id, &name, &active, &tags))
+	assert.Equal(t, int32(1), id)
+	assert.Equal(t, "johndoe", name)
+	assert.Equal(t, true, active)
+	assert.Equal(t, []string{"admin", "staff"}, tags)
+}
+
+func TestLoadMockRow_OK(t *testing.T) {
+	mockRow, err := pgxpoolgo.LoadMockRow("user.json", pgxpoolgo.FixtureTypes{"balance": "numeric"})
+	assert.Nil(t, err)
+
+	var id int
+	var name string
+	var balance pgtype.Numeric
+
+	assert.Nil(t, mockRow.Compose().Scan(&id, &name, &balance))
+	assert.Equal(t, 1, id)
+	assert.Equal(t, "johndoe", name)
+	assert.Equal(t, pgtype.Present, balance.Status)
+	assert.Equal(t, int32(-2), balance.Exp)
+}
+
+func TestLoadMockRow_NotSingle(t *testing.T) {
+	_, err := pgxpoolgo.LoadMockRow("users.json", nil)
+	assert.EqualError(t, err, "expected a single record in fixture users.json, got 2")
+}
+
+func TestLoadMockRows_NotFound(t *testing.T) {
+	_, err := pgxpoolgo.LoadMockRows("missing.csv", nil)
+	assert.NotNil(t, err)
+}
+
+func TestNewMockRowsFromCSV_Invalid(t *testing.T) {
+	_, err := pgxpoolgo.NewMockRowsFromCSV(strings.NewReader("id\nabc\n"), pgxpoolgo.FixtureTypes{"id": "int8"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "can't convert value of column 'id' in record 0")
+
+	_, err = pgxpoolgo.NewMockRowsFromCSV(strings.NewReader("id\n1\n"), pgxpoolgo.FixtureTypes{"id": "nosuchtype"})
+	assert.EqualError(t, err, "unknown type nosuchtype of column id")
+}
+
+func TestNewMockRowsFromYAML_OK(t *testing.T) {
+	mockRows, err := pgxpoolgo.NewMockRowsFromYAML(strings.NewReader("id: 1\nname: johndoe\n"), nil)
+	assert.Nil(t, err)
+	rows := mockRows.Compose()
+
+	assert.Equal(t, true, rows.Next())
+	values, err := rows.Values()
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{int64(1), "johndoe"}, values)
+}
@@ -0,0 +1,673 @@
+package pgxpoolgo
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// golden records the calls of a RecordingPool, or replays the calls recorded in a golden file for a ReplayPool.
+type golden struct {
+	mu     sync.Mutex
+	name   string
+	calls  []*goldenCall
+	replay bool
+	pos    int
+	nextTx int
+	errs   []string
+}
+
+// goldenFile is the content of a golden file.
+type goldenFile struct {
+	Calls []*goldenCall `json:"calls"`
+}
+
+// goldenCall is a call recorded in a golden file: the request the replayed call must match, and its result.
+type goldenCall struct {
+	goldenRequest
+	goldenResult
+}
+
+type goldenRequest struct {
+	Method      string          `json:"method,omitempty"`
+	Tx          int             `json:"tx,omitempty"`
+	Name        string          `json:"name,omitempty"`
+	SQL         string          `json:"sql,omitempty"`
+	Args        []*string       `json:"args,omitempty"`
+	TxOptions   *pgx.TxOptions  `json:"tx_options,omitempty"`
+	Table       pgx.Identifier  `json:"table,omitempty"`
+	CopyColumns []string        `json:"copy_columns,omitempty"`
+	CopyRows    [][]*string     `json:"copy_rows,omitempty"`
+	Batch       []goldenRequest `json:"batch,omitempty"`
+}
+
+type goldenResult struct {
+	Columns      []goldenColumn `json:"columns,omitempty"`
+	Rows         [][]*string    `json:"rows,omitempty"`
+	CommandTag   string         `json:"command_tag,omitempty"`
+	RowsAffected int64          `json:"rows_affected,omitempty"`
+	ParamOIDs    []uint32       `json:"param_oids,omitempty"`
+	Results      []goldenResult `json:"results,omitempty"`
+	RowsError    *goldenError   `json:"rows_error,omitempty"`
+	Error        *goldenError   `json:"error,omitempty"`
+}
+
+type goldenColumn struct {
+	Name   string `json:"name"`
+	OID    uint32 `json:"oid"`
+	Format int16  `json:"format,omitempty"`
+}
+
+// goldenError is a recorded error. *pgconn.PgError keeps its fields, so that it is replayed as a *pgconn.PgError,
+// and the errors of pgx and context are replayed as themselves.
+type goldenError struct {
+	Message        string `json:"message"`
+	Is             string `json:"is,omitempty"`
+	Severity       string `json:"severity,omitempty"`
+	Code           string `json:"code,omitempty"`
+	Detail         string `json:"detail,omitempty"`
+	Hint           string `json:"hint,omitempty"`
+	SchemaName     string `json:"schema_name,omitempty"`
+	TableName      string `json:"table_name,omitempty"`
+	ColumnName     string `json:"column_name,omitempty"`
+	ConstraintName string `json:"constraint_name,omitempty"`
+}
+
+// goldenSentinels are the errors replayed as themselves, by name.
+var goldenSentinels = map[string]error{
+	"pgx.ErrNoRows":            pgx.ErrNoRows,
+	"pgx.ErrTxClosed":          pgx.ErrTxClosed,
+	"pgx.ErrTxCommitRollback":  pgx.ErrTxCommitRollback,
+	"context.Canceled":         context.Canceled,
+	"context.DeadlineExceeded": context.DeadlineExceeded,
+}
+
+// replayedError is a replayed error which wraps a sentinel error with the message it was recorded with.
+type replayedError struct {
+	message string
+	err     error
+}
+
+func (e *replayedError) Error() string {
+	return e.message
+}
+
+func (e *replayedError) Unwrap() error {
+	return e.err
+}
+
+func newGoldenError(err error) *goldenError {
+	if err == nil {
+		return nil
+	}
+	ge := &goldenError{Message: err.Error()}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		ge.Severity = pgErr.Severity
+		ge.Code = pgErr.Code
+		ge.Message = pgErr.Message
+		ge.Detail = pgErr.Detail
+		ge.Hint = pgErr.Hint
+		ge.SchemaName = pgErr.SchemaName
+		ge.TableName = pgErr.TableName
+		ge.ColumnName = pgErr.ColumnName
+		ge.ConstraintName = pgErr.ConstraintName
+		return ge
+	}
+	for name, sentinel := range goldenSentinels {
+		if errors.Is(err, sentinel) {
+			ge.Is = name
+		}
+	}
+	return ge
+}
+
+func (ge *goldenError) err() error {
+	if ge == nil {
+		return nil
+	}
+	if ge.Code != "" {
+		return &pgconn.PgError{
+			Severity:       ge.Severity,
+			Code:           ge.Code,
+			Message:        ge.Message,
+			Detail:         ge.Detail,
+			Hint:           ge.Hint,
+			SchemaName:     ge.SchemaName,
+			TableName:      ge.TableName,
+			ColumnName:     ge.ColumnName,
+			ConstraintName: ge.ConstraintName,
+		}
+	}
+	if sentinel, ok := goldenSentinels[ge.Is]; ok {
+		if sentinel.Error() == ge.Message {
+			return sentinel
+		}
+		return &replayedError{message: ge.Message, err: sentinel}
+	}
+	return errors.New(ge.Message)
+}
+
+// openGolden opens the golden file at path, looked up in testdata/ the way fixtures are.
+func openGolden(name string, path string) (*golden, error) {
+	file, err := openFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var content goldenFile
+	if err := json.NewDecoder(file).Decode(&content); err != nil {
+		return nil, fmt.Errorf("can't read golden file %s: %w", path, err)
+	}
+	return &golden{name: name, calls: content.Calls, replay: true}, nil
+}
+
+// save writes the recorded calls to the golden file at path, creating its directory.
+func (g *golden) save(path string) error {
+	var content bytes.Buffer
+	enc := json.NewEncoder(&content)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	g.mu.Lock()
+	err := enc.Encode(goldenFile{Calls: g.calls})
+	g.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content.Bytes(), 0o644)
+}
+
+// play runs the call req. When recording, record runs the call and sets its result, which is recorded. When
+// replaying, the result is the one recorded for the next call, which must match req.
+func (g *golden) play(req goldenRequest, record func(*goldenResult)) (*goldenResult, error) {
+	if !g.replay {
+		call := &goldenCall{goldenRequest: req}
+		record(&call.goldenResult)
+		g.mu.Lock()
+		g.calls = append(g.calls, call)
+		g.mu.Unlock()
+		return &call.goldenResult, nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	got, _ := json.Marshal(req)
+	if g.pos >= len(g.calls) {
+		return nil, g.fail("%s: unexpected call %d, %s, after the %d calls of the golden file", g.name, g.pos, got, len(g.calls))
+	}
+	want, _ := json.Marshal(g.calls[g.pos].goldenRequest)
+	if string(got) != string(want) {
+		return nil, g.fail("%s: call %d diverges from the golden file, expected %s, got %s", g.name, g.pos, want, got)
+	}
+	g.pos++
+	return &g.calls[g.pos-1].goldenResult, nil
+}
+
+func (g *golden) fail(format string, args ...interface{}) error {
+	err := fmt.Errorf(format, args...)
+	g.errs = append(g.errs, err.Error())
+	return err
+}
+
+// begun returns the id of the transaction begun by a call.
+func (g *golden) begun() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.nextTx++
+	return g.nextTx
+}
+
+// expectationsWereMet returns the divergences of the replayed calls, and the calls of the golden file which were not
+// replayed.
+func (g *golden) expectationsWereMet() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	errs := g.errs
+	if g.replay && g.pos < len(g.calls) {
+		call, _ := json.Marshal(g.calls[g.pos].goldenRequest)
+		errs = append(errs, fmt.Sprintf("%s: %d of %d calls of the golden file were not replayed, next is %s", g.name, len(g.calls)-g.pos, len(g.calls), call))
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// goldenQuerier is what the recorded pool and its transactions have in common.
+type goldenQuerier interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// goldenConn records or replays the calls of the pool, with tx 0, or of the transaction tx. q runs the calls when
+// recording, and is nil when replaying.
+type goldenConn struct {
+	g  *golden
+	tx int
+	q  goldenQuerier
+}
+
+func (c *goldenConn) request(method string, sql string, args []interface{}) goldenRequest {
+	return goldenRequest{Method: method, Tx: c.tx, SQL: sql, Args: goldenTexts(args)}
+}
+
+func (c *goldenConn) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	res, err := c.g.play(c.request("Exec", sql, arguments), func(res *goldenResult) {
+		tag, err := c.q.Exec(ctx, sql, arguments...)
+		res.CommandTag = string(tag)
+		res.Error = newGoldenError(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := res.Error.err(); err != nil {
+		return nil, err
+	}
+	return pgconn.CommandTag(res.CommandTag), nil
+}
+
+func (c *goldenConn) query(ctx context.Context, method string, sql string, args []interface{}) (pgx.Rows, error) {
+	res, err := c.g.play(c.request(method, sql, args), func(res *goldenResult) {
+		rows, err := c.q.Query(ctx, sql, args...)
+		recordRows(res, rows, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.rows()
+}
+
+func (c *goldenConn) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return c.query(ctx, "Query", sql, args)
+}
+
+func (c *goldenConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	rows, err := c.query(ctx, "QueryRow", sql, args)
+	return &firstRow{rows: rows, err: err}
+}
+
+func (c *goldenConn) QueryFunc(ctx context.Context, sql string, args []interface{}, scans []interface{}, f func(pgx.QueryFuncRow) error) (pgconn.CommandTag, error) {
+	rows, err := c.query(ctx, "QueryFunc", sql, args)
+	if err != nil {
+		return nil, err
+	}
+	return queryFunc(rows, scans, f)
+}
+
+func (c *goldenConn) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	req := goldenRequest{Method: "SendBatch", Tx: c.tx}
+	for _, q := range queuedQueries(b) {
+		req.Batch = append(req.Batch, goldenRequest{SQL: q.sql, Args: goldenTexts(q.args)})
+	}
+	res, err := c.g.play(req, func(res *goldenResult) {
+		br := c.q.SendBatch(ctx, b)
+		res.Results = make([]goldenResult, b.Len())
+		for i := range res.Results {
+			rows, err := br.Query()
+			recordRows(&res.Results[i], rows, err)
+		}
+		res.Error = newGoldenError(br.Close())
+	})
+	if err != nil {
+		return &errBatchResults{err: err}
+	}
+	results := make([]func() (pgx.Rows, error), len(res.Results))
+	for i := range res.Results {
+		results[i] = res.Results[i].rows
+	}
+	return &rowsBatchResults{results: results, err: res.Error.err()}
+}
+
+func (c *goldenConn) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	var copied [][]interface{}
+	req := goldenRequest{Method: "CopyFrom", Tx: c.tx, Table: tableName, CopyColumns: columnNames}
+	for rowSrc.Next() {
+		values, err := rowSrc.Values()
+		if err != nil {
+			return 0, err
+		}
+		copied = append(copied, append([]interface{}(nil), values...))
+		req.CopyRows = append(req.CopyRows, goldenTexts(values))
+	}
+	if err := rowSrc.Err(); err != nil {
+		return 0, err
+	}
+	res, err := c.g.play(req, func(res *goldenResult) {
+		n, err := c.q.CopyFrom(ctx, tableName, columnNames, pgx.CopyFromRows(copied))
+		res.RowsAffected = n
+		res.Error = newGoldenError(err)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected, res.Error.err()
+}
+
+// begin records or replays the beginning of a transaction with start, which begins it when recording.
+func (c *goldenConn) begin(req goldenRequest, start func() (pgx.Tx, error)) (pgx.Tx, error) {
+	req.Tx = c.tx
+	var tx pgx.Tx
+	res, err := c.g.play(req, func(res *goldenResult) {
+		var err error
+		tx, err = start()
+		res.Error = newGoldenError(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := res.Error.err(); err != nil {
+		return nil, err
+	}
+	gtx := &goldenTx{goldenConn: goldenConn{g: c.g, tx: c.g.begun()}, real: tx}
+	if tx != nil {
+		gtx.q = tx
+	}
+	return gtx, nil
+}
+
+// goldenTx is a transaction of a RecordingPool or a ReplayPool.
+type goldenTx struct {
+	goldenConn
+	real pgx.Tx
+}
+
+func (t *goldenTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	return t.begin(goldenRequest{Method: "Begin"}, func() (pgx.Tx, error) {
+		return t.real.Begin(ctx)
+	})
+}
+
+func (t *goldenTx) BeginFunc(ctx context.Context, f func(pgx.Tx) error) error {
+	tx, err := t.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	return beginFunc(ctx, tx, f)
+}
+
+func (t *goldenTx) end(method string, end func() error) error {
+	res, err := t.g.play(goldenRequest{Method: method, Tx: t.tx}, func(res *goldenResult) {
+		res.Error = newGoldenError(end())
+	})
+	if err != nil {
+		return err
+	}
+	return res.Error.err()
+}
+
+func (t *goldenTx) Commit(ctx context.Context) error {
+	return t.end("Commit", func() error {
+		return t.real.Commit(ctx)
+	})
+}
+
+func (t *goldenTx) Rollback(ctx context.Context) error {
+	return t.end("Rollback", func() error {
+		return t.real.Rollback(ctx)
+	})
+}
+
+func (t *goldenTx) Prepare(ctx context.Context, name string, sql string) (*pgconn.StatementDescription, error) {
+	res, err := t.g.play(goldenRequest{Method: "Prepare", Tx: t.tx, Name: name, SQL: sql}, func(res *goldenResult) {
+		sd, err := t.real.Prepare(ctx, name, sql)
+		res.Error = newGoldenError(err)
+		if sd != nil {
+			res.ParamOIDs = sd.ParamOIDs
+			res.Columns = goldenColumns(sd.Fields)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := res.Error.err(); err != nil {
+		return nil, err
+	}
+	sd := &pgconn.StatementDescription{Name: name, SQL: sql, ParamOIDs: res.ParamOIDs}
+	for _, column := range res.Columns {
+		sd.Fields = append(sd.Fields, column.def())
+	}
+	return sd, nil
+}
+
+// LargeObjects returns the large objects of the recorded transaction, which are not recorded, and an unusable
+// pgx.LargeObjects when replaying.
+func (t *goldenTx) LargeObjects() pgx.LargeObjects {
+	if t.real == nil {
+		return pgx.LargeObjects{}
+	}
+	return t.real.LargeObjects()
+}
+
+// Conn returns the connection of the recorded transaction, and nil when replaying.
+func (t *goldenTx) Conn() *pgx.Conn {
+	if t.real == nil {
+		return nil
+	}
+	return t.real.Conn()
+}
+
+// firstRow is the pgx.Row of QueryRow, reading the first row of rows the way pgx does.
+type firstRow struct {
+	rows pgx.Rows
+	err  error
+}
+
+func (r *firstRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+	r.rows.Close()
+	return r.rows.Err()
+}
+
+// rowsBatchResults returns the results of the queued queries of a batch, read as rows by results, and the error of
+// Close.
+type rowsBatchResults struct {
+	results []func() (pgx.Rows, error)
+	err     error
+	index   int
+	closed  bool
+}
+
+func (br *rowsBatchResults) next() (pgx.Rows, error) {
+	if br.closed {
+		return nil, errors.New("batch already closed")
+	}
+	if br.index >= len(br.results) {
+		return nil, errors.New("no result")
+	}
+	br.index++
+	return br.results[br.index-1]()
+}
+
+func (br *rowsBatchResults) Exec() (pgconn.CommandTag, error) {
+	rows, err := br.next()
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+	}
+	rows.Close()
+	return rows.CommandTag(), rows.Err()
+}
+
+func (br *rowsBatchResults) Query() (pgx.Rows, error) {
+	return br.next()
+}
+
+func (br *rowsBatchResults) QueryRow() pgx.Row {
+	rows, err := br.next()
+	return &firstRow{rows: rows, err: err}
+}
+
+func (br *rowsBatchResults) QueryFunc(scans []interface{}, f func(pgx.QueryFuncRow) error) (pgconn.CommandTag, error) {
+	rows, err := br.next()
+	if err != nil {
+		return nil, err
+	}
+	return queryFunc(rows, scans, f)
+}
+
+func (br *rowsBatchResults) Close() error {
+	br.closed = true
+	return br.err
+}
+
+// recordRows reads rows, returned by Query with err, into res.
+func recordRows(res *goldenResult, rows pgx.Rows, err error) {
+	if err != nil {
+		res.Error = newGoldenError(err)
+		return
+	}
+	defer rows.Close()
+	ci := pgtype.NewConnInfo()
+	res.Columns = goldenColumns(rows.FieldDescriptions())
+	for rows.Next() {
+		raw := rows.RawValues()
+		values := make([]*string, len(raw))
+		for i, src := range raw {
+			values[i] = goldenValueText(ci, rows.FieldDescriptions()[i], src)
+		}
+		res.Rows = append(res.Rows, values)
+	}
+	rows.Close()
+	res.CommandTag = string(rows.CommandTag())
+	res.RowsError = newGoldenError(rows.Err())
+}
+
+// rows returns the recorded rows, with their values decoded from the text they were recorded as, or the error of
+// the query.
+func (res *goldenResult) rows() (pgx.Rows, error) {
+	if err := res.Error.err(); err != nil {
+		return nil, err
+	}
+	ci := pgtype.NewConnInfo()
+	columns := make([]*MockColumn, len(res.Columns))
+	dts := make([]*pgtype.DataType, len(res.Columns))
+	for i, column := range res.Columns {
+		columns[i] = NewMockColumn(column.Name).WithOID(column.OID).WithFormat(column.Format)
+		dts[i], _ = ci.DataTypeForOID(column.OID)
+	}
+	mr := NewMockRowsWithColumns(columns...).AddCommandTag(pgconn.CommandTag(res.CommandTag))
+	for r, row := range res.Rows {
+		values := make([]interface{}, len(row))
+		for i, text := range row {
+			if text == nil {
+				continue
+			}
+			value, err := fixtureValue(ci, dts[i], *text)
+			if err != nil {
+				return nil, fmt.Errorf("can't replay value of column '%s' in row %d: %w", res.Columns[i].Name, r, err)
+			}
+			values[i] = value
+		}
+		mr.AddRow(values...)
+	}
+	if err := res.RowsError.err(); err != nil {
+		mr.RowError(len(res.Rows), err)
+	}
+	return mr.Compose(), nil
+}
+
+func goldenColumns(defs []pgproto3.FieldDescription) []goldenColumn {
+	columns := make([]goldenColumn, len(defs))
+	for i, def := range defs {
+		columns[i] = goldenColumn{Name: string(def.Name), OID: def.DataTypeOID, Format: def.Format}
+	}
+	return columns
+}
+
+func (gc goldenColumn) def() pgproto3.FieldDescription {
+	return NewMockColumn(gc.Name).WithOID(gc.OID).WithFormat(gc.Format).def
+}
+
+// goldenValueText returns the text of the value src of the column def, as sent by the server, or nil for NULL.
+func goldenValueText(ci *pgtype.ConnInfo, def pgproto3.FieldDescription, src []byte) *string {
+	if src == nil {
+		return nil
+	}
+	text := string(src)
+	if def.Format == pgtype.BinaryFormatCode {
+		if dt, ok := ci.DataTypeForOID(def.DataTypeOID); ok {
+			value := pgtype.NewValue(dt.Value)
+			decoder, _ := value.(pgtype.BinaryDecoder)
+			encoder, _ := value.(pgtype.TextEncoder)
+			if decoder != nil && encoder != nil && decoder.DecodeBinary(ci, src) == nil {
+				if b, err := encoder.EncodeText(ci, nil); err == nil {
+					text = string(b)
+				}
+			}
+		}
+	}
+	return &text
+}
+
+// goldenTexts returns the text of the values of args, the way they are compared when replaying.
+func goldenTexts(args []interface{}) []*string {
+	ci := pgtype.NewConnInfo()
+	texts := make([]*string, len(args))
+	for i, arg := range args {
+		texts[i] = goldenText(ci, arg)
+	}
+	return texts
+}
+
+// goldenText returns the text of the argument v as pgtype encodes it, or nil for NULL.
+func goldenText(ci *pgtype.ConnInfo, v interface{}) *string {
+	if v == nil {
+		return nil
+	}
+	if dt, ok := ci.DataTypeForValue(v); ok {
+		if formatCode, src, err := encodeValue(ci, dt, v, pgtype.TextFormatCode); err == nil && formatCode == pgtype.TextFormatCode {
+			if src == nil {
+				return nil
+			}
+			text := string(src)
+			return &text
+		}
+	}
+	if valuer, ok := v.(driver.Valuer); ok {
+		if value, err := valuer.Value(); err == nil {
+			return goldenText(ci, value)
+		}
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		return goldenText(ci, rv.Elem().Interface())
+	}
+	if b, ok := v.([]byte); ok {
+		text := string(b)
+		return &text
+	}
+	text := fmt.Sprint(v)
+	return &text
+}
@@ -0,0 +1,210 @@
+package pgxpoolgo
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// updateGoldenEnv is the environment variable which makes GoldenPool record the golden files again when it is true.
+const updateGoldenEnv = "PGXPOOLGO_UPDATE_GOLDEN"
+
+// RecordingPool is a Pool which runs every call on the wrapped pool, and records the calls of Exec, Query, QueryRow,
+// QueryFunc, SendBatch, CopyFrom, Ping and transactions, with their SQL, arguments, rows, command tags and errors,
+// to be saved into a golden file for ReplayPool. The rows of Query are read when it is called, and replayed to the
+// caller. Acquired connections and large objects are not recorded.
+type RecordingPool struct {
+	goldenConn
+	pool Pool
+	path string
+}
+
+// NewRecordingPool records the calls of pool into the golden file at path, written by Save.
+func NewRecordingPool(pool Pool, path string) *RecordingPool {
+	return &RecordingPool{goldenConn: goldenConn{g: &golden{name: "RecordingPool"}, q: pool}, pool: pool, path: path}
+}
+
+// Save writes the recorded calls to the golden file.
+func (rp *RecordingPool) Save() error {
+	return rp.g.save(rp.path)
+}
+
+func (rp *RecordingPool) Close() {
+	rp.pool.Close()
+}
+
+func (rp *RecordingPool) Acquire(ctx context.Context) (*pgxpool.Conn, error) {
+	return rp.pool.Acquire(ctx)
+}
+
+func (rp *RecordingPool) AcquireFunc(ctx context.Context, f func(*pgxpool.Conn) error) error {
+	return rp.pool.AcquireFunc(ctx, f)
+}
+
+func (rp *RecordingPool) AcquireAllIdle(ctx context.Context) []*pgxpool.Conn {
+	return rp.pool.AcquireAllIdle(ctx)
+}
+
+func (rp *RecordingPool) Config() *pgxpool.Config {
+	return rp.pool.Config()
+}
+
+func (rp *RecordingPool) Stat() *pgxpool.Stat {
+	return rp.pool.Stat()
+}
+
+func (rp *RecordingPool) Begin(ctx context.Context) (pgx.Tx, error) {
+	return rp.begin(goldenRequest{Method: "Begin"}, func() (pgx.Tx, error) {
+		return rp.pool.Begin(ctx)
+	})
+}
+
+func (rp *RecordingPool) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	return rp.begin(goldenRequest{Method: "BeginTx", TxOptions: &txOptions}, func() (pgx.Tx, error) {
+		return rp.pool.BeginTx(ctx, txOptions)
+	})
+}
+
+func (rp *RecordingPool) BeginFunc(ctx context.Context, f func(pgx.Tx) error) error {
+	return poolBeginFunc(ctx, rp.Begin, f)
+}
+
+func (rp *RecordingPool) BeginTxFunc(ctx context.Context, txOptions pgx.TxOptions, f func(pgx.Tx) error) error {
+	return poolBeginFunc(ctx, func(ctx context.Context) (pgx.Tx, error) {
+		return rp.BeginTx(ctx, txOptions)
+	}, f)
+}
+
+func (rp *RecordingPool) Ping(ctx context.Context) error {
+	return playPing(&rp.goldenConn, func() error {
+		return rp.pool.Ping(ctx)
+	})
+}
+
+// ReplayPool is a Pool which replays the calls recorded by RecordingPool in a golden file, in the recorded order.
+// A call which diverges from the next recorded call, by its method, SQL, arguments or transaction, fails, and the
+// divergences are reported by ExpectationsWereMet. Acquire and AcquireFunc fail, and Config and Stat return nil.
+type ReplayPool struct {
+	goldenConn
+}
+
+// NewReplayPool replays the golden file at path, looked up in testdata/ when a relative path does not exist.
+func NewReplayPool(path string) (*ReplayPool, error) {
+	g, err := openGolden("ReplayPool", path)
+	if err != nil {
+		return nil, err
+	}
+	return &ReplayPool{goldenConn: goldenConn{g: g}}, nil
+}
+
+// ExpectationsWereMet returns the calls which diverged from the golden file, and the calls of the golden file which
+// were not replayed.
+func (rp *ReplayPool) ExpectationsWereMet() error {
+	return rp.g.expectationsWereMet()
+}
+
+func (rp *ReplayPool) Close() {}
+
+func (rp *ReplayPool) Acquire(_ context.Context) (*pgxpool.Conn, error) {
+	return nil, errors.New("ReplayPool: Acquire is not replayed")
+}
+
+func (rp *ReplayPool) AcquireFunc(_ context.Context, _ func(*pgxpool.Conn) error) error {
+	return errors.New("ReplayPool: AcquireFunc is not replayed")
+}
+
+func (rp *ReplayPool) AcquireAllIdle(_ context.Context) []*pgxpool.Conn {
+	return nil
+}
+
+func (rp *ReplayPool) Config() *pgxpool.Config {
+	return nil
+}
+
+func (rp *ReplayPool) Stat() *pgxpool.Stat {
+	return nil
+}
+
+func (rp *ReplayPool) Begin(_ context.Context) (pgx.Tx, error) {
+	return rp.begin(goldenRequest{Method: "Begin"}, nil)
+}
+
+func (rp *ReplayPool) BeginTx(_ context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	return rp.begin(goldenRequest{Method: "BeginTx", TxOptions: &txOptions}, nil)
+}
+
+func (rp *ReplayPool) BeginFunc(ctx context.Context, f func(pgx.Tx) error) error {
+	return poolBeginFunc(ctx, rp.Begin, f)
+}
+
+func (rp *ReplayPool) BeginTxFunc(ctx context.Context, txOptions pgx.TxOptions, f func(pgx.Tx) error) error {
+	return poolBeginFunc(ctx, func(ctx context.Context) (pgx.Tx, error) {
+		return rp.BeginTx(ctx, txOptions)
+	}, f)
+}
+
+func (rp *ReplayPool) Ping(_ context.Context) error {
+	return playPing(&rp.goldenConn, nil)
+}
+
+func poolBeginFunc(ctx context.Context, begin func(context.Context) (pgx.Tx, error), f func(pgx.Tx) error) error {
+	tx, err := begin(ctx)
+	if err != nil {
+		return err
+	}
+	return beginFunc(ctx, tx, f)
+}
+
+func playPing(c *goldenConn, ping func() error) error {
+	res, err := c.g.play(goldenRequest{Method: "Ping"}, func(res *goldenResult) {
+		res.Error = newGoldenError(ping())
+	})
+	if err != nil {
+		return err
+	}
+	return res.Error.err()
+}
+
+type goldenTestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Cleanup(func())
+}
+
+// GoldenPool returns a ReplayPool of the golden file testdata/name, whose divergences fail t at cleanup. When the
+// tests run with PGXPOOLGO_UPDATE_GOLDEN=true, it returns a RecordingPool of the pool returned by connect instead,
+// which records the golden file again at cleanup.
+func GoldenPool(t goldenTestingT, name string, connect func() (Pool, error)) Pool {
+	t.Helper()
+	path := filepath.Join(fixtureDir, name)
+	if update, _ := strconv.ParseBool(os.Getenv(updateGoldenEnv)); update {
+		pool, err := connect()
+		if err != nil {
+			t.Fatalf("can't connect to record %s: %v", path, err)
+			return nil
+		}
+		rp := NewRecordingPool(pool, path)
+		t.Cleanup(func() {
+			if err := rp.Save(); err != nil {
+				t.Errorf("can't save %s: %v", path, err)
+			}
+		})
+		return rp
+	}
+	rp, err := NewReplayPool(path)
+	if err != nil {
+		t.Fatalf("can't replay %s, record it with %s=true: %v", path, updateGoldenEnv, err)
+		return nil
+	}
+	t.Cleanup(func() {
+		if err := rp.ExpectationsWereMet(); err != nil {
+			t.Errorf("%s", err)
+		}
+	})
+	return rp
+}
@@ -0,0 +1,162 @@
+package pgxpoolgo_test
+
+import (
+	"context"
+	"errors"
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type goldenUser struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+}
+
+type goldenOutcome struct {
+	Tag      string
+	Users    []goldenUser
+	FindErr  error
+	TxErr    error
+	Balances []int64
+}
+
+func goldenRegisterUser(ctx context.Context, pool pgxpoolgo.Pool) goldenOutcome {
+	var outcome goldenOutcome
+
+	tag, _ := pool.Exec(ctx, `INSERT INTO users (name) VALUES ($1)`, "johndoe")
+	outcome.Tag = tag.String()
+
+	rows, err := pool.Query(ctx, `SELECT id, name, created_at FROM users WHERE id > $1`, 0)
+	if err == nil {
+		for rows.Next() {
+			var user goldenUser
+			_ = rows.Scan(&user.ID, &user.Name, &user.CreatedAt)
+			outcome.Users = append(outcome.Users, user)
+		}
+		rows.Close()
+	}
+
+	var id int64
+	outcome.FindErr = pool.QueryRow(ctx, `SELECT id FROM users WHERE name = $1`, "nobody").Scan(&id)
+
+	outcome.TxErr = pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `INSERT INTO users (name) VALUES ($1)`, "johndoe")
+		return err
+	})
+
+	b := &pgx.Batch{}
+	b.Queue(`SELECT balance FROM accounts WHERE user_id = $1`, int64(1))
+	b.Queue(`SELECT balance FROM accounts WHERE user_id = $1`, int64(2))
+	br := pool.SendBatch(ctx, b)
+	for i := 0; i < b.Len(); i++ {
+		var balance int64
+		_ = br.QueryRow().Scan(&balance)
+		outcome.Balances = append(outcome.Balances, balance)
+	}
+	_ = br.Close()
+
+	return outcome
+}
+
+func goldenMockPool(t *testing.T) *pgxpoolgo.MockPool {
+	createdAt := time.Date(2022, 10, 1, 8, 30, 0, 0, time.UTC)
+	mockPool := pgxpoolgo.NewMockPool(t)
+	mockTx := pgxpoolgo.NewMockTx(t)
+
+	mockPool.ExpectExec(`INSERT INTO users (name) VALUES ($1)`).WithArgs("johndoe").
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("INSERT", 1))
+	mockPool.ExpectQuery(`SELECT id, name, created_at FROM users WHERE id > $1`).WithArgs(0).
+		WillReturnRows(pgxpoolgo.NewMockRows([]string{"id", "name", "created_at"}).
+			AddRow(int64(1), "johndoe", createdAt).
+			AddCommandTag(pgxpoolgo.NewMockCommandTag("SELECT", 1)))
+	mockPool.ExpectQuery(`SELECT id FROM users WHERE name = $1`).WithArgs("nobody").
+		WillReturnRows(pgxpoolgo.NewMockRows([]string{"id"}))
+	mockPool.ExpectBegin().WillReturnTx(mockTx)
+	mockTx.ExpectExec(`INSERT INTO users (name) VALUES ($1)`).WithArgs("johndoe").
+		WillReturnError(&pgconn.PgError{Code: pgxpoolgo.ErrDBCodeDuplicateKey, Message: "duplicate key value violates unique constraint \"users_name_key\""})
+	mockTx.ExpectRollback()
+	mockPool.ExpectSendBatch().WillReturnBatchResults(pgxpoolgo.NewMockBatchResults().
+		AddQuery(pgxpoolgo.NewMockRows([]string{"balance"}).AddRow(int64(100))).
+		AddQuery(pgxpoolgo.NewMockRows([]string{"balance"}).AddRow(int64(250))).
+		Compose())
+
+	return mockPool
+}
+
+func TestRecordingPool_Replay(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "users.json")
+
+	recordingPool := pgxpoolgo.NewRecordingPool(goldenMockPool(t), path)
+	recorded := goldenRegisterUser(ctx, recordingPool)
+	assert.Nil(t, recordingPool.Save())
+
+	replayPool, err := pgxpoolgo.NewReplayPool(path)
+	assert.Nil(t, err)
+	replayed := goldenRegisterUser(ctx, replayPool)
+	assert.Nil(t, replayPool.ExpectationsWereMet())
+
+	assert.Equal(t, recorded, replayed)
+	assert.Equal(t, "INSERT 1", replayed.Tag)
+	assert.Equal(t, 1, len(replayed.Users))
+	assert.Equal(t, int64(1), replayed.Users[0].ID)
+	assert.Equal(t, true, replayed.Users[0].CreatedAt.Equal(time.Date(2022, 10, 1, 8, 30, 0, 0, time.UTC)))
+	assert.Equal(t, pgx.ErrNoRows, replayed.FindErr)
+	assert.Equal(t, true, pgxpoolgo.ErrDB(replayed.TxErr).IsDuplicateKey())
+	assert.Equal(t, []int64{100, 250}, replayed.Balances)
+}
+
+func TestReplayPool_Divergence(t *testing.T) {
+	ctx := context.Background()
+	replayPool, err := pgxpoolgo.NewReplayPool("golden/users.json")
+	assert.Nil(t, err)
+
+	_, err = replayPool.Exec(ctx, `INSERT INTO users (name) VALUES ($1)`, "janedoe")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "ReplayPool: call 0 diverges from the golden file")
+
+	err = replayPool.ExpectationsWereMet()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `"args":["janedoe"]`)
+	assert.Contains(t, err.Error(), "calls of the golden file were not replayed")
+}
+
+func TestGoldenPool_OK(t *testing.T) {
+	ctx := context.Background()
+	pool := pgxpoolgo.GoldenPool(t, "golden/users.json", func() (pgxpoolgo.Pool, error) {
+		return goldenMockPool(t), nil
+	})
+
+	outcome := goldenRegisterUser(ctx, pool)
+	assert.Equal(t, "INSERT 1", outcome.Tag)
+	assert.Equal(t, true, errors.Is(outcome.FindErr, pgx.ErrNoRows))
+	assert.Equal(t, []int64{100, 250}, outcome.Balances)
+}
+
+func TestGoldenPool_Update(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join("testdata", "golden", "users_update.json")
+	t.Cleanup(func() {
+		data, err := os.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Contains(t, string(data), "INSERT INTO users")
+		_ = os.Remove(path)
+	})
+	t.Setenv("PGXPOOLGO_UPDATE_GOLDEN", "true")
+
+	pool := pgxpoolgo.GoldenPool(t, "golden/users_update.json", func() (pgxpoolgo.Pool, error) {
+		return goldenMockPool(t), nil
+	})
+	_, ok := pool.(*pgxpoolgo.RecordingPool)
+	assert.Equal(t, true, ok)
+
+	outcome := goldenRegisterUser(ctx, pool)
+	assert.Equal(t, "INSERT 1", outcome.Tag)
+}
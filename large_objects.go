@@ -0,0 +1,280 @@
+package pgxpoolgo
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"io"
+	"sync"
+)
+
+// LargeObjects is the mockable interface of pgx.LargeObjects, which Tx.LargeObjects returns as a struct.
+type LargeObjects interface {
+	Create(ctx context.Context, oid uint32) (uint32, error)
+	Open(ctx context.Context, oid uint32, mode pgx.LargeObjectMode) (LargeObject, error)
+	Unlink(ctx context.Context, oid uint32) error
+}
+
+// LargeObject is the mockable interface of pgx.LargeObject.
+type LargeObject interface {
+	Read(p []byte) (int, error)
+	Write(p []byte) (int, error)
+	Seek(offset int64, whence int) (int64, error)
+	Tell() (int64, error)
+	Truncate(size int64) error
+	Close() error
+}
+
+type largeObjects struct {
+	pgx.LargeObjects
+}
+
+// NewLargeObjects wraps the large objects of the transaction tx into LargeObjects.
+func NewLargeObjects(tx pgx.Tx) LargeObjects {
+	return &largeObjects{tx.LargeObjects()}
+}
+
+func (lo *largeObjects) Open(ctx context.Context, oid uint32, mode pgx.LargeObjectMode) (LargeObject, error) {
+	obj, err := lo.LargeObjects.Open(ctx, oid, mode)
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// firstLargeObjectOID is the first OID PostgreSQL assigns to objects created by users.
+const firstLargeObjectOID = 16384
+
+const (
+	errCodeUndefinedObject              = "42704"
+	errCodeObjectNotInPrerequisiteState = "55000"
+	errCodeInvalidParameterValue        = "22023"
+)
+
+// MockLargeObjects mocks LargeObjects with large objects kept in memory.
+type MockLargeObjects struct {
+	mu      sync.Mutex
+	objects map[uint32][]byte
+	nextOID uint32
+	nextFD  int32
+}
+
+type mockLargeObject struct {
+	objects *MockLargeObjects
+	oid     uint32
+	fd      int32
+	mode    pgx.LargeObjectMode
+	pos     int64
+	closed  bool
+}
+
+// NewMockLargeObjects mocks LargeObjects.
+func NewMockLargeObjects() *MockLargeObjects {
+	return &MockLargeObjects{
+		objects: make(map[uint32][]byte),
+		nextOID: firstLargeObjectOID,
+	}
+}
+
+// AddObject stores a large object with the content data, to be read by the code under test.
+func (mlo *MockLargeObjects) AddObject(oid uint32, data []byte) *MockLargeObjects {
+	mlo.mu.Lock()
+	defer mlo.mu.Unlock()
+	mlo.objects[oid] = append([]byte(nil), data...)
+	return mlo
+}
+
+// Object returns the content of the large object oid, and whether it exists.
+func (mlo *MockLargeObjects) Object(oid uint32) ([]byte, bool) {
+	mlo.mu.Lock()
+	defer mlo.mu.Unlock()
+	data, ok := mlo.objects[oid]
+	return append([]byte(nil), data...), ok
+}
+
+// Create creates an empty large object. A new OID is assigned when oid is 0.
+func (mlo *MockLargeObjects) Create(_ context.Context, oid uint32) (uint32, error) {
+	mlo.mu.Lock()
+	defer mlo.mu.Unlock()
+	if oid == 0 {
+		for _, ok := mlo.objects[mlo.nextOID]; ok; _, ok = mlo.objects[mlo.nextOID] {
+			mlo.nextOID++
+		}
+		oid = mlo.nextOID
+	}
+	if _, ok := mlo.objects[oid]; ok {
+		return 0, &pgconn.PgError{
+			Severity: "ERROR",
+			Code:     ErrDBCodeDuplicateKey,
+			Message:  `duplicate key value violates unique constraint "pg_largeobject_metadata_oid_index"`,
+			Detail:   fmt.Sprintf("Key (oid)=(%d) already exists.", oid),
+		}
+	}
+	mlo.objects[oid] = []byte{}
+	return oid, nil
+}
+
+// Open opens the large object oid with mode.
+func (mlo *MockLargeObjects) Open(_ context.Context, oid uint32, mode pgx.LargeObjectMode) (LargeObject, error) {
+	mlo.mu.Lock()
+	defer mlo.mu.Unlock()
+	if _, ok := mlo.objects[oid]; !ok {
+		return nil, errLargeObjectNotFound(oid)
+	}
+	fd := mlo.nextFD
+	mlo.nextFD++
+	return &mockLargeObject{objects: mlo, oid: oid, fd: fd, mode: mode}, nil
+}
+
+// Unlink removes the large object oid.
+func (mlo *MockLargeObjects) Unlink(_ context.Context, oid uint32) error {
+	mlo.mu.Lock()
+	defer mlo.mu.Unlock()
+	if _, ok := mlo.objects[oid]; !ok {
+		return errLargeObjectNotFound(oid)
+	}
+	delete(mlo.objects, oid)
+	return nil
+}
+
+func errLargeObjectNotFound(oid uint32) error {
+	return &pgconn.PgError{
+		Severity: "ERROR",
+		Code:     errCodeUndefinedObject,
+		Message:  fmt.Sprintf("large object %d does not exist", oid),
+	}
+}
+
+// data returns the content of the large object, failing the way PostgreSQL does when the descriptor was closed or
+// the large object was unlinked.
+func (o *mockLargeObject) data() ([]byte, error) {
+	if o.closed {
+		return nil, &pgconn.PgError{
+			Severity: "ERROR",
+			Code:     errCodeUndefinedObject,
+			Message:  fmt.Sprintf("invalid large-object descriptor: %d", o.fd),
+		}
+	}
+	data, ok := o.objects.objects[o.oid]
+	if !ok {
+		return nil, errLargeObjectNotFound(o.oid)
+	}
+	return data, nil
+}
+
+func (o *mockLargeObject) writable() error {
+	if o.mode&pgx.LargeObjectModeWrite == 0 {
+		return &pgconn.PgError{
+			Severity: "ERROR",
+			Code:     errCodeObjectNotInPrerequisiteState,
+			Message:  fmt.Sprintf("large object descriptor %d was not opened for writing", o.fd),
+		}
+	}
+	return nil
+}
+
+func (o *mockLargeObject) Read(p []byte) (int, error) {
+	o.objects.mu.Lock()
+	defer o.objects.mu.Unlock()
+	data, err := o.data()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	if o.pos < int64(len(data)) {
+		n = copy(p, data[o.pos:])
+	}
+	o.pos += int64(n)
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (o *mockLargeObject) Write(p []byte) (int, error) {
+	o.objects.mu.Lock()
+	defer o.objects.mu.Unlock()
+	data, err := o.data()
+	if err != nil {
+		return 0, err
+	}
+	if err := o.writable(); err != nil {
+		return 0, err
+	}
+	if end := o.pos + int64(len(p)); end > int64(len(data)) {
+		data = append(data, make([]byte, end-int64(len(data)))...)
+	}
+	n := copy(data[o.pos:], p)
+	o.pos += int64(n)
+	o.objects.objects[o.oid] = data
+	return n, nil
+}
+
+func (o *mockLargeObject) Seek(offset int64, whence int) (int64, error) {
+	o.objects.mu.Lock()
+	defer o.objects.mu.Unlock()
+	data, err := o.data()
+	if err != nil {
+		return 0, err
+	}
+	pos := offset
+	switch whence {
+	case io.SeekCurrent:
+		pos += o.pos
+	case io.SeekEnd:
+		pos += int64(len(data))
+	}
+	if pos < 0 {
+		return 0, &pgconn.PgError{
+			Severity: "ERROR",
+			Code:     errCodeInvalidParameterValue,
+			Message:  fmt.Sprintf("invalid seek offset: %d", pos),
+		}
+	}
+	o.pos = pos
+	return pos, nil
+}
+
+func (o *mockLargeObject) Tell() (int64, error) {
+	o.objects.mu.Lock()
+	defer o.objects.mu.Unlock()
+	if _, err := o.data(); err != nil {
+		return 0, err
+	}
+	return o.pos, nil
+}
+
+func (o *mockLargeObject) Truncate(size int64) error {
+	o.objects.mu.Lock()
+	defer o.objects.mu.Unlock()
+	data, err := o.data()
+	if err != nil {
+		return err
+	}
+	if err := o.writable(); err != nil {
+		return err
+	}
+	if size < 0 {
+		return &pgconn.PgError{
+			Severity: "ERROR",
+			Code:     errCodeInvalidParameterValue,
+			Message:  fmt.Sprintf("invalid large object truncation target: %d", size),
+		}
+	}
+	if size > int64(len(data)) {
+		data = append(data, make([]byte, size-int64(len(data)))...)
+	}
+	o.objects.objects[o.oid] = data[:size]
+	return nil
+}
+
+func (o *mockLargeObject) Close() error {
+	o.objects.mu.Lock()
+	defer o.objects.mu.Unlock()
+	if _, err := o.data(); err != nil {
+		return err
+	}
+	o.closed = true
+	return nil
+}
@@ -0,0 +1,94 @@
+package pgxpoolgo_test
+
+import (
+	"context"
+	"errors"
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func largeObjectsStoreAvatar(ctx context.Context, lo pgxpoolgo.LargeObjects, avatar []byte) (uint32, error) {
+	oid, err := lo.Create(ctx, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	obj, err := lo.Open(ctx, oid, pgx.LargeObjectModeWrite)
+	if err != nil {
+		return 0, err
+	}
+	defer obj.Close()
+
+	if _, err = obj.Write(avatar); err != nil {
+		return 0, err
+	}
+
+	return oid, nil
+}
+
+func TestLargeObjectsStoreAvatar_OK(t *testing.T) {
+	ctx := context.Background()
+	mockLargeObjects := pgxpoolgo.NewMockLargeObjects()
+	assert.Implements(t, (*pgxpoolgo.LargeObjects)(nil), mockLargeObjects)
+
+	oid, err := largeObjectsStoreAvatar(ctx, mockLargeObjects, []byte("avatar"))
+	assert.Nil(t, err)
+	data, ok := mockLargeObjects.Object(oid)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, []byte("avatar"), data)
+}
+
+func TestLargeObjects_ReadSeekTruncate(t *testing.T) {
+	ctx := context.Background()
+	mockLargeObjects := pgxpoolgo.NewMockLargeObjects().AddObject(42, []byte("hello world"))
+
+	obj, err := mockLargeObjects.Open(ctx, 42, pgx.LargeObjectModeRead|pgx.LargeObjectModeWrite)
+	assert.Nil(t, err)
+
+	pos, err := obj.Seek(-5, io.SeekEnd)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(6), pos)
+
+	buf := make([]byte, 8)
+	n, err := obj.Read(buf)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, "world", string(buf[:n]))
+
+	tell, err := obj.Tell()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(11), tell)
+
+	assert.Nil(t, obj.Truncate(5))
+	assert.Nil(t, obj.Close())
+	data, _ := mockLargeObjects.Object(42)
+	assert.Equal(t, []byte("hello"), data)
+
+	_, err = obj.Read(buf)
+	assert.NotNil(t, err)
+}
+
+func TestLargeObjects_Errors(t *testing.T) {
+	ctx := context.Background()
+	mockLargeObjects := pgxpoolgo.NewMockLargeObjects().AddObject(42, []byte("hello"))
+
+	var pgErr *pgconn.PgError
+
+	_, err := mockLargeObjects.Create(ctx, 42)
+	assert.Equal(t, true, pgxpoolgo.ErrDB(err).IsDuplicateKey())
+
+	_, err = mockLargeObjects.Open(ctx, 7, pgx.LargeObjectModeRead)
+	assert.Equal(t, true, errors.As(err, &pgErr))
+	assert.Equal(t, "large object 7 does not exist", pgErr.Message)
+
+	obj, err := mockLargeObjects.Open(ctx, 42, pgx.LargeObjectModeRead)
+	assert.Nil(t, err)
+	_, err = obj.Write([]byte("world"))
+	assert.NotNil(t, err)
+
+	assert.Nil(t, mockLargeObjects.Unlink(ctx, 42))
+	assert.NotNil(t, mockLargeObjects.Unlink(ctx, 42))
+}
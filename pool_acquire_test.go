@@ -0,0 +1,60 @@
+package pgxpoolgo_test
+
+import (
+	"context"
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func poolAcquireWithAdvisoryLock(ctx context.Context, pool pgxpoolgo.ConnPool, key int64) error {
+	conn, err := pool.AcquireConn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err = conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, key); err != nil {
+		return err
+	}
+
+	if _, err = conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, key); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func TestPoolAcquireWithAdvisoryLock_OK(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+	assert.Implements(t, (*pgxpoolgo.ConnPool)(nil), mockPool)
+
+	mockConn := pgxpoolgo.NewMockConn(t)
+	assert.Implements(t, (*pgxpoolgo.Conn)(nil), mockConn)
+
+	mockPool.ExpectAcquire().WillReturnConn(mockConn)
+	mockConn.ExpectExec(`SELECT pg_advisory_lock($1)`).WithArgs(int64(42)).WillReturnResult(pgxpoolgo.NewMockCommandTag("SELECT", 1))
+	mockConn.ExpectExec(`SELECT pg_advisory_unlock($1)`).WithArgs(int64(42)).WillReturnResult(pgxpoolgo.NewMockCommandTag("SELECT", 1))
+	mockConn.ExpectRelease()
+
+	err := poolAcquireWithAdvisoryLock(ctx, mockPool, 42)
+	assert.Nil(t, err)
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestPoolAcquireWithAdvisoryLock_On(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+	mockConn := pgxpoolgo.NewMockConn(t)
+
+	mockPool.On("AcquireConn", ctx).Return(mockConn, nil).Once()
+	mockConn.On("Exec", ctx, `SELECT pg_advisory_lock($1)`, int64(42)).Return(pgxpoolgo.NewMockCommandTag("SELECT", 1), nil).Once()
+	mockConn.On("Exec", ctx, `SELECT pg_advisory_unlock($1)`, int64(42)).Return(pgxpoolgo.NewMockCommandTag("SELECT", 1), nil).Once()
+	mockConn.On("Release").Return().Once()
+
+	err := poolAcquireWithAdvisoryLock(ctx, mockPool, 42)
+	assert.Nil(t, err)
+	assert.Equal(t, true, mockPool.AssertExpectations(t))
+	assert.Equal(t, true, mockConn.AssertExpectations(t))
+}
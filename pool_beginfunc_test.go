@@ -0,0 +1,140 @@
+package pgxpoolgo_test
+
+import (
+	"context"
+	"errors"
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func poolBeginFuncRenameUser(ctx context.Context, pool pgxpoolgo.Pool, from, to string) error {
+	return pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		commandTag, err := tx.Exec(ctx, `UPDATE users SET username = $1 WHERE username = $2`, to, from)
+		if err != nil {
+			return err
+		}
+		if commandTag.RowsAffected() < 1 {
+			return errors.New("no user was renamed")
+		}
+		return nil
+	})
+}
+
+func TestPoolBeginFuncRenameUser_OK(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+	mockTx := pgxpoolgo.NewMockTx(t)
+
+	mockPool.ExpectBegin().WillReturnTx(mockTx)
+	mockTx.ExpectExec(`UPDATE users SET username = $1 WHERE username = $2`).WithArgs("janedoe", "johndoe").
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("UPDATE", 1))
+	mockTx.ExpectCommit()
+
+	err := poolBeginFuncRenameUser(ctx, mockPool, "johndoe", "janedoe")
+	assert.Nil(t, err)
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestPoolBeginFuncRenameUser_Rollback(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockTx := pgxpoolgo.NewMockTx(t)
+
+	mockPool.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.Serializable}).WillReturnTx(mockTx)
+	mockTx.ExpectRollback()
+
+	err := mockPool.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable}, func(tx pgx.Tx) error {
+		return tx.Rollback(ctx)
+	})
+	assert.Equal(t, pgx.ErrTxClosed, err)
+
+	mockTx = pgxpoolgo.NewMockTx(t)
+	mockPool.ExpectBegin().WillReturnTx(mockTx)
+	mockTx.ExpectExec(`UPDATE users SET username = $1 WHERE username = $2`).WithArgs("janedoe", "johndoe").
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("UPDATE", 0))
+	mockTx.ExpectRollback()
+
+	err = poolBeginFuncRenameUser(ctx, mockPool, "johndoe", "janedoe")
+	assert.EqualError(t, err, "no user was renamed")
+}
+
+func TestPoolBeginFuncRenameUser_Panic(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+	mockTx := pgxpoolgo.NewMockTx(t)
+
+	mockPool.ExpectBegin().WillReturnTx(mockTx)
+	mockTx.ExpectRollback()
+
+	assert.Panics(t, func() {
+		_ = mockPool.BeginFunc(ctx, func(tx pgx.Tx) error {
+			panic("unexpected nil user")
+		})
+	})
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestPoolAcquireConnFunc_OK(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+	mockConn := pgxpoolgo.NewMockConn(t)
+
+	mockPool.ExpectAcquire().WillReturnConn(mockConn)
+	mockConn.ExpectExec(`SELECT pg_advisory_lock($1)`).WithArgs(int64(42)).WillReturnResult(pgxpoolgo.NewMockCommandTag("SELECT", 1))
+	mockConn.ExpectRelease()
+
+	err := mockPool.AcquireConnFunc(ctx, func(conn pgxpoolgo.Conn) error {
+		_, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, int64(42))
+		return err
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestPoolAcquireConnFunc_ReleasedOnError(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+	mockConn := pgxpoolgo.NewMockConn(t)
+
+	mockPool.ExpectAcquire().WillReturnConn(mockConn)
+	mockConn.ExpectExec(`SELECT pg_advisory_lock($1)`).WithArgs(int64(42)).WillReturnError(errors.New("lock failed"))
+	mockConn.ExpectRelease()
+
+	err := mockPool.AcquireConnFunc(ctx, func(conn pgxpoolgo.Conn) error {
+		_, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, int64(42))
+		return err
+	})
+	assert.Equal(t, "lock failed", err.Error())
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestPoolBeginFunc_DefaultTx(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockPool.ExpectBegin()
+	mockPool.ExpectBegin()
+
+	assert.Nil(t, mockPool.BeginFunc(ctx, func(pgx.Tx) error {
+		return nil
+	}))
+	assert.Equal(t, "rename failed", mockPool.BeginFunc(ctx, func(pgx.Tx) error {
+		return errors.New("rename failed")
+	}).Error())
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestPoolAcquireConnFunc_DefaultConn(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockPool.ExpectAcquire()
+
+	assert.Nil(t, mockPool.AcquireConnFunc(ctx, func(pgxpoolgo.Conn) error {
+		return nil
+	}))
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
@@ -0,0 +1,159 @@
+package pgxpoolgo_test
+
+import (
+	"context"
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func poolConcurrencyProcessJob(ctx context.Context, pool pgxpoolgo.Pool, id int) error {
+	var payload string
+	if err := pool.QueryRow(ctx, `SELECT payload FROM jobs WHERE id = $1`, id).Scan(&payload); err != nil {
+		return err
+	}
+	b := &pgx.Batch{}
+	b.Queue(`INSERT INTO results (job_id, payload) VALUES ($1, $2)`, id, payload)
+	br := pool.SendBatch(ctx, b)
+	if _, err := br.Exec(); err != nil {
+		return err
+	}
+	if err := br.Close(); err != nil {
+		return err
+	}
+	_, err := pool.Exec(ctx, `UPDATE jobs SET done = true WHERE id = $1`, id)
+	return err
+}
+
+// poolConcurrencyRunWorkers processes the jobs from as many goroutines, at most limit at once when limit is set.
+func poolConcurrencyRunWorkers(ctx context.Context, pool pgxpoolgo.Pool, jobs int, limit int) []error {
+	var sem chan struct{}
+	if limit > 0 {
+		sem = make(chan struct{}, limit)
+	}
+	errs := make([]error, jobs)
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			if sem != nil {
+				sem <- struct{}{}
+				defer func() { <-sem }()
+			}
+			errs[id] = poolConcurrencyProcessJob(ctx, pool, id)
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+func TestPoolConcurrency_OK(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockPool.MatchExpectationsInOrder(false)
+	mockPool.ExpectQueryRow(`SELECT payload FROM jobs WHERE id = $1`).WithArgs(pgxpoolgo.AnyOfType[int]()).
+		WillReturnRow(pgxpoolgo.NewMockRow([]string{"payload"}).AddRow("{}")).
+		Times(20)
+	mockPool.ExpectSendBatch().
+		WillReturnBatchResults(pgxpoolgo.NewMockBatchResults().AddExec(pgxpoolgo.NewMockCommandTag("INSERT", 1)).Compose()).
+		Times(20)
+	mockPool.ExpectExec(`UPDATE jobs SET done = true WHERE id = $1`).WithArgs(pgxpoolgo.AnyArg()).
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("UPDATE", 1)).
+		WillDelayFor(time.Millisecond).
+		Times(20)
+
+	for _, err := range poolConcurrencyRunWorkers(ctx, mockPool, 20, 0) {
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestPoolConcurrency_MaxInFlight(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockPool.MatchExpectationsInOrder(false)
+	mockPool.ExpectMaxInFlight(3)
+	mockPool.ExpectQueryRow(`SELECT payload FROM jobs WHERE id = $1`).
+		WillReturnRow(pgxpoolgo.NewMockRow([]string{"payload"}).AddRow("{}")).
+		WillDelayFor(5 * time.Millisecond).
+		Times(12)
+	mockPool.ExpectSendBatch().
+		WillReturnBatchResults(pgxpoolgo.NewMockBatchResults().AddExec(pgxpoolgo.NewMockCommandTag("INSERT", 1)).Compose()).
+		Times(12)
+	mockPool.ExpectExec(`UPDATE jobs SET done = true WHERE id = $1`).
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("UPDATE", 1)).
+		Times(12)
+
+	for _, err := range poolConcurrencyRunWorkers(ctx, mockPool, 12, 3) {
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, true, mockPool.MaxInFlight() <= 3)
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestPoolConcurrency_MaxInFlightExceeded(t *testing.T) {
+	ctx := context.Background()
+	mockPool := &pgxpoolgo.MockPool{}
+
+	mockPool.MatchExpectationsInOrder(false)
+	mockPool.ExpectMaxInFlight(3)
+	mockPool.ExpectQueryRow(`SELECT payload FROM jobs WHERE id = $1`).
+		WillReturnRow(pgxpoolgo.NewMockRow([]string{"payload"}).AddRow("{}")).
+		WillDelayFor(50 * time.Millisecond).
+		Times(8)
+	mockPool.ExpectSendBatch().
+		WillReturnBatchResults(pgxpoolgo.NewMockBatchResults().AddExec(pgxpoolgo.NewMockCommandTag("INSERT", 1)).Compose()).
+		Times(8)
+	mockPool.ExpectExec(`UPDATE jobs SET done = true WHERE id = $1`).
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("UPDATE", 1)).
+		Times(9)
+
+	for _, err := range poolConcurrencyRunWorkers(ctx, mockPool, 8, 0) {
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, true, mockPool.MaxInFlight() > 3)
+	err := mockPool.ExpectationsWereMet()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "calls were in flight at once, but at most 3 were expected")
+	assert.Contains(t, err.Error(), "which was called 8 of 9 times")
+}
+
+func TestPoolConcurrency_Rows(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+	mockRows := pgxpoolgo.NewMockRows([]string{"id"})
+	for i := 0; i < 100; i++ {
+		mockRows.AddRow(int64(i))
+	}
+
+	mockPool.ExpectQuery(`SELECT id FROM jobs`).WillReturnRows(mockRows)
+
+	rows, err := mockPool.Query(ctx, `SELECT id FROM jobs`)
+	assert.Nil(t, err)
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		for rows.Next() {
+			var id int64
+			_ = rows.Scan(&id)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		rows.Close()
+	}()
+	go func() {
+		defer wg.Done()
+		_ = mockPool.ExpectationsWereMet()
+	}()
+	wg.Wait()
+	assert.Nil(t, rows.Err())
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
@@ -0,0 +1,110 @@
+package pgxpoolgo_test
+
+import (
+	"context"
+	"errors"
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type copyFromUser struct {
+	Username string
+	Email    string
+}
+
+// copyFromReusedSource is a pgx.CopyFromSource which returns the values of every row in the same slice.
+type copyFromReusedSource struct {
+	users  []copyFromUser
+	i      int
+	values []interface{}
+}
+
+func (s *copyFromReusedSource) Next() bool {
+	s.i++
+	return s.i <= len(s.users)
+}
+
+func (s *copyFromReusedSource) Values() ([]interface{}, error) {
+	s.values[0], s.values[1] = s.users[s.i-1].Username, s.users[s.i-1].Email
+	return s.values, nil
+}
+
+func (s *copyFromReusedSource) Err() error {
+	return nil
+}
+
+func poolCopyFromInsertUsers(ctx context.Context, pool pgxpoolgo.Pool, users []copyFromUser) (int64, error) {
+	return pool.CopyFrom(ctx, pgx.Identifier{"public", "users"}, []string{"username", "email"}, pgx.CopyFromSlice(len(users), func(i int) ([]interface{}, error) {
+		return []interface{}{users[i].Username, users[i].Email}, nil
+	}))
+}
+
+func TestPoolCopyFromInsertUsers_OK(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	users := []copyFromUser{{"johndoe", "johndoe@email.com"}, {"janedoe", "janedoe@email.com"}}
+	expectedCopyFrom := mockPool.ExpectCopyFrom(pgx.Identifier{"public", "users"}, []string{"username", "email"}).
+		WithRows([]interface{}{"johndoe", "johndoe@email.com"}, []interface{}{"janedoe", "janedoe@email.com"})
+
+	n, err := poolCopyFromInsertUsers(ctx, mockPool, users)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, [][]interface{}{{"johndoe", "johndoe@email.com"}, {"janedoe", "janedoe@email.com"}}, expectedCopyFrom.CopiedRows())
+}
+
+func TestPoolCopyFromInsertUsers_FailAfter(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	users := []copyFromUser{{"johndoe", "johndoe@email.com"}, {"janedoe", "janedoe@email.com"}}
+	expectedCopyFrom := mockPool.ExpectCopyFrom(pgx.Identifier{"public", "users"}, []string{"username", "email"}).
+		WillFailAfter(1, errors.New("connection reset"))
+
+	n, err := poolCopyFromInsertUsers(ctx, mockPool, users)
+	assert.EqualError(t, err, "connection reset")
+	assert.Equal(t, int64(0), n)
+	assert.Equal(t, [][]interface{}{{"johndoe", "johndoe@email.com"}}, expectedCopyFrom.CopiedRows())
+}
+
+func TestPoolCopyFromInsertUsers_RowDiffers(t *testing.T) {
+	ctx := context.Background()
+	mockPool := &pgxpoolgo.MockPool{}
+
+	users := []copyFromUser{{"johndoe", "johndoe@email.com"}}
+	mockPool.ExpectCopyFrom(pgx.Identifier{"public", "users"}, []string{"username", "email"}).
+		WithRows([]interface{}{"johndoe", "john@email.com"})
+
+	_, err := poolCopyFromInsertUsers(ctx, mockPool, users)
+	assert.EqualError(t, err, "CopyFrom \"public\".\"users\": copied row 0 does not match: argument 1 expected [string - john@email.com] does not match actual [string - johndoe@email.com]")
+	assert.NotNil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestPoolCopyFromInsertUsers_ColumnCount(t *testing.T) {
+	ctx := context.Background()
+	mockPool := &pgxpoolgo.MockPool{}
+
+	mockPool.ExpectCopyFrom(pgx.Identifier{"users"}, []string{"username", "email"})
+
+	_, err := mockPool.CopyFrom(ctx, pgx.Identifier{"users"}, []string{"username", "email"}, pgx.CopyFromRows([][]interface{}{{"johndoe"}}))
+	assert.EqualError(t, err, "expected 2 values, got 1 values")
+	err = mockPool.ExpectationsWereMet()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "CopyFrom \"users\": expected 2 values, got 1 values")
+}
+
+func TestPoolCopyFromInsertUsers_ReusedValues(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	users := []copyFromUser{{"johndoe", "johndoe@email.com"}, {"janedoe", "janedoe@email.com"}}
+	expectedCopyFrom := mockPool.ExpectCopyFrom(pgx.Identifier{"public", "users"}, []string{"username", "email"}).
+		WithRows([]interface{}{"johndoe", "johndoe@email.com"}, []interface{}{"janedoe", "janedoe@email.com"})
+
+	n, err := mockPool.CopyFrom(ctx, pgx.Identifier{"public", "users"}, []string{"username", "email"}, &copyFromReusedSource{users: users, values: make([]interface{}, 2)})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, [][]interface{}{{"johndoe", "johndoe@email.com"}, {"janedoe", "janedoe@email.com"}}, expectedCopyFrom.CopiedRows())
+}
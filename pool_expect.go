@@ -0,0 +1,140 @@
+package pgxpoolgo
+
+import (
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/mock"
+)
+
+func (_m *MockPool) state() *mockState {
+	return stateOf(&_m.Mock, "MockPool")
+}
+
+// Called answers a mocked call from the expectations registered with the Expect methods, and falls back to the
+// expectations registered with On. Expectations can be registered with the Expect methods while other goroutines call
+// the mock, but On must be called before, testify giving no safe way to read them concurrently.
+func (_m *MockPool) Called(arguments ...interface{}) mock.Arguments {
+	method := calledMethod()
+	leave := _m.state().enter()
+	defer leave()
+	ret, ok := _m.state().called(method, arguments)
+	if !ok {
+		ret = _m.Mock.MethodCalled(method, arguments...)
+	}
+	_m.state().returned(method, arguments, ret)
+	return ret
+}
+
+// AssertExpectations asserts that everything specified with On and Return, and every expectation registered with
+// the Expect methods, was in fact called as expected.
+func (_m *MockPool) AssertExpectations(t mock.TestingT) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	ok := _m.Mock.AssertExpectations(t)
+	return _m.state().assertExpectations(t) && ok
+}
+
+// MatchExpectationsInOrder sets whether the calls of the mock, and of the mocks it hands out unless they are set
+// otherwise, must match the expectations in the order they were registered. They are matched in order by default.
+func (_m *MockPool) MatchExpectationsInOrder(b bool) {
+	_m.state().matchInOrder(b)
+}
+
+// ExpectationsWereMet checks whether all the expectations registered with the Expect methods, on the mock and on
+// every mock it handed out, were met and no unexpected call was made.
+func (_m *MockPool) ExpectationsWereMet() error {
+	return _m.state().expectationsWereMet()
+}
+
+// UseQueryMatcher sets the QueryMatcher of the Expect methods. QueryMatcherNormalized is used by default.
+func (_m *MockPool) UseQueryMatcher(qm QueryMatcher) {
+	_m.state().useQueryMatcher(qm)
+}
+
+// ExpectMaxInFlight expects at most n calls in flight at once, on the mock and on every mock it handed out, like
+// the limit of a semaphore or the size of the pool. ExpectationsWereMet fails when more were. A call is in flight
+// until it returns, after the delay of WillDelayFor.
+func (_m *MockPool) ExpectMaxInFlight(n int) {
+	_m.state().expectMaxInFlight(n)
+}
+
+// MaxInFlight returns the most calls which were in flight at once, on the mock and on every mock it handed out.
+func (_m *MockPool) MaxInFlight() int {
+	return _m.state().maxInFlight()
+}
+
+// ExpectExec expects Pool.Exec to be called with the expected SQL.
+func (_m *MockPool) ExpectExec(expectedSQL string) *ExpectedExec {
+	e := &ExpectedExec{}
+	e.expectSQL = expectedSQL
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectQuery expects Pool.Query to be called with the expected SQL.
+func (_m *MockPool) ExpectQuery(expectedSQL string) *ExpectedQuery {
+	e := &ExpectedQuery{}
+	e.expectSQL = expectedSQL
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectQueryRow expects Pool.QueryRow to be called with the expected SQL.
+func (_m *MockPool) ExpectQueryRow(expectedSQL string) *ExpectedQueryRow {
+	e := &ExpectedQueryRow{}
+	e.expectSQL = expectedSQL
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectQueryFunc expects Pool.QueryFunc to be called with the expected SQL.
+func (_m *MockPool) ExpectQueryFunc(expectedSQL string) *ExpectedQueryFunc {
+	e := &ExpectedQueryFunc{}
+	e.expectSQL = expectedSQL
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectSendBatch expects Pool.SendBatch to be called.
+func (_m *MockPool) ExpectSendBatch() *ExpectedSendBatch {
+	e := &ExpectedSendBatch{}
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectCopyFrom expects Pool.CopyFrom to be called with the expected table name and column names.
+func (_m *MockPool) ExpectCopyFrom(tableName pgx.Identifier, columnNames []string) *ExpectedCopyFrom {
+	e := &ExpectedCopyFrom{tableName: tableName, columns: columnNames}
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectBegin expects Pool.Begin, Pool.BeginTx, Pool.BeginFunc or Pool.BeginTxFunc to be called. BeginFunc and
+// BeginTxFunc run their function with the MockTx, which is then committed, or rolled back when the function fails.
+func (_m *MockPool) ExpectBegin() *ExpectedBegin {
+	e := &ExpectedBegin{}
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectBeginTx expects Pool.BeginTx or Pool.BeginTxFunc to be called with the expected transaction options.
+func (_m *MockPool) ExpectBeginTx(txOptions pgx.TxOptions) *ExpectedBegin {
+	e := &ExpectedBegin{opts: &txOptions}
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectAcquire expects ConnPool.AcquireConn or ConnPool.AcquireConnFunc to be called. AcquireConnFunc runs its
+// function with the MockConn, then releases it with Release, as pgxpool.Pool.AcquireFunc does.
+func (_m *MockPool) ExpectAcquire() *ExpectedAcquire {
+	e := &ExpectedAcquire{}
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectAcquireAllIdle expects ConnPool.AcquireAllIdleConns to be called.
+func (_m *MockPool) ExpectAcquireAllIdle() *ExpectedAcquireAllIdle {
+	e := &ExpectedAcquireAllIdle{}
+	_m.state().expect(e)
+	return e
+}
@@ -0,0 +1,121 @@
+package pgxpoolgo_test
+
+import (
+	"context"
+	"errors"
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func poolExpectGetUserEmails(ctx context.Context, pool pgxpoolgo.Pool, active bool) ([]string, error) {
+	var emails []string
+
+	rows, err := pool.Query(ctx, `
+		SELECT email
+		FROM users
+		WHERE active = $1
+	`, active)
+	if err != nil {
+		return emails, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var email string
+		if err = rows.Scan(&email); err != nil {
+			return emails, err
+		}
+		emails = append(emails, email)
+	}
+
+	return emails, nil
+}
+
+func poolExpectDeactivateUser(ctx context.Context, pool pgxpoolgo.Pool, username string) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := tx.Exec(ctx, `UPDATE users SET active = false WHERE username = $1`, username)
+	if err != nil {
+		return err
+	}
+	if commandTag.RowsAffected() < 1 {
+		_ = tx.Rollback(ctx)
+		return errors.New("no user was deactivated")
+	}
+
+	return tx.Commit(ctx)
+}
+
+func TestPoolExpectQuery_OK(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockRows := pgxpoolgo.NewMockRows([]string{"email"}).AddRow("johndoe@email.com").AddRow("janedoe@email.com")
+	mockPool.ExpectQuery(`select email from users where active = $1`).WithArgs(true).WillReturnRows(mockRows)
+
+	emails, err := poolExpectGetUserEmails(ctx, mockPool, true)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"johndoe@email.com", "janedoe@email.com"}, emails)
+	assert.Equal(t, true, mockPool.AssertExpectations(t))
+}
+
+func TestPoolExpectQuery_Regexp(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+	mockPool.UseQueryMatcher(pgxpoolgo.QueryMatcherRegexp)
+
+	mockRows := pgxpoolgo.NewMockRows([]string{"email"}).AddRow("johndoe@email.com")
+	mockPool.ExpectQuery(`SELECT email FROM users WHERE .+`).WillReturnRows(mockRows)
+
+	emails, err := poolExpectGetUserEmails(ctx, mockPool, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"johndoe@email.com"}, emails)
+}
+
+func TestPoolExpectQuery_Unexpected(t *testing.T) {
+	ctx := context.Background()
+	mockPool := &pgxpoolgo.MockPool{}
+
+	mockPool.ExpectQuery(`SELECT email FROM user WHERE active = $1`).WithArgs(true)
+
+	_, err := poolExpectGetUserEmails(ctx, mockPool, true)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "next expectation is: MockPool: ExpectedQuery")
+	assert.Contains(t, err.Error(), "expected: select email from user where active=$1")
+	assert.Contains(t, err.Error(), "actual:   select email from users where active=$1")
+}
+
+func TestPoolExpectQuery_UnexpectedNonASCII(t *testing.T) {
+	ctx := context.Background()
+	mockPool := &pgxpoolgo.MockPool{}
+
+	mockPool.ExpectQuery(`SELECT email FROM users WHERE name = 'zoë' AND id = $1`)
+
+	_, err := mockPool.Query(ctx, `SELECT email FROM users WHERE name = 'zoë' AND age = $1`, 1)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "expected: select email from users where name='zoë' and id=$1\n"+
+		"    actual:   select email from users where name='zoë' and age=$1\n"+
+		"              "+strings.Repeat(" ", len([]rune("select email from users where name='zoë' and ")))+"^")
+}
+
+func TestPoolExpectBegin_OK(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+	mockTx := pgxpoolgo.NewMockTx(t)
+
+	mockPool.ExpectBegin().WillReturnTx(mockTx)
+	mockTx.ExpectExec(`UPDATE users
+		SET active = false
+		WHERE username = $1`).WithArgs("johndoe").WillReturnResult(pgxpoolgo.NewMockCommandTag("UPDATE", 1))
+	mockTx.ExpectCommit()
+
+	err := poolExpectDeactivateUser(ctx, mockPool, "johndoe")
+	assert.Nil(t, err)
+	assert.Equal(t, true, mockPool.AssertExpectations(t))
+	assert.Equal(t, true, mockTx.AssertExpectations(t))
+}
@@ -13,7 +13,7 @@ import (
 	pgxpool "github.com/jackc/pgx/v4/pgxpool"
 )
 
-// MockPool is an autogenerated mock type for the Pool type
+// MockPool is an autogenerated mock type for the ConnPool type
 type MockPool struct {
 	mock.Mock
 }
@@ -57,6 +57,59 @@ func (_m *MockPool) AcquireAllIdle(ctx context.Context) []*pgxpool.Conn {
 	return r0
 }
 
+// AcquireAllIdleConns provides a mock function with given fields: ctx
+func (_m *MockPool) AcquireAllIdleConns(ctx context.Context) []Conn {
+	ret := _m.Called(ctx)
+
+	var r0 []Conn
+	if rf, ok := ret.Get(0).(func(context.Context) []Conn); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Conn)
+		}
+	}
+
+	return r0
+}
+
+// AcquireConn provides a mock function with given fields: ctx
+func (_m *MockPool) AcquireConn(ctx context.Context) (Conn, error) {
+	ret := _m.Called(ctx)
+
+	var r0 Conn
+	if rf, ok := ret.Get(0).(func(context.Context) Conn); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(Conn)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AcquireConnFunc provides a mock function with given fields: ctx, f
+func (_m *MockPool) AcquireConnFunc(ctx context.Context, f func(Conn) error) error {
+	ret := _m.Called(ctx, f)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(Conn) error) error); ok {
+		r0 = rf(ctx, f)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // AcquireFunc provides a mock function with given fields: ctx, f
 func (_m *MockPool) AcquireFunc(ctx context.Context, f func(*pgxpool.Conn) error) error {
 	ret := _m.Called(ctx, f)
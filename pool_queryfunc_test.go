@@ -0,0 +1,68 @@
+package pgxpoolgo_test
+
+import (
+	"context"
+	"errors"
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func poolQueryFuncGetUsernames(ctx context.Context, pool pgxpoolgo.Pool, limit int) ([]string, pgconn.CommandTag, error) {
+	var usernames []string
+	var username string
+
+	commandTag, err := pool.QueryFunc(ctx, `SELECT username FROM users LIMIT $1`, []interface{}{limit}, []interface{}{&username},
+		func(row pgx.QueryFuncRow) error {
+			if username == "" {
+				return errors.New("empty username")
+			}
+			usernames = append(usernames, username)
+			return nil
+		})
+
+	return usernames, commandTag, err
+}
+
+func TestPoolQueryFuncGetUsernames_OK(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockRows := pgxpoolgo.NewMockRows([]string{"username"}).AddRow("johndoe").AddRow("janedoe").
+		AddCommandTag(pgxpoolgo.NewMockCommandTag("SELECT", 2))
+	mockPool.ExpectQueryFunc(`SELECT username FROM users LIMIT $1`).WithArgs(10).WillReturnRows(mockRows)
+
+	usernames, commandTag, err := poolQueryFuncGetUsernames(ctx, mockPool, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"johndoe", "janedoe"}, usernames)
+	assert.Equal(t, int64(2), commandTag.RowsAffected())
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestPoolQueryFuncGetUsernames_CallbackError(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockRows := pgxpoolgo.NewMockRows([]string{"username"}).AddRow("johndoe").AddRow("").AddRow("janedoe")
+	mockPool.ExpectQueryFunc(`SELECT username FROM users LIMIT $1`).WithArgs(10).WillReturnRows(mockRows)
+
+	usernames, commandTag, err := poolQueryFuncGetUsernames(ctx, mockPool, 10)
+	assert.EqualError(t, err, "empty username")
+	assert.Nil(t, commandTag)
+	assert.Equal(t, []string{"johndoe"}, usernames)
+}
+
+func TestPoolQueryFuncGetUsernames_RowError(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	connReset := errors.New("connection reset by peer")
+	mockRows := pgxpoolgo.NewMockRows([]string{"username"}).AddRow("johndoe").AddRow("janedoe").RowError(1, connReset)
+	mockPool.ExpectQueryFunc(`SELECT username FROM users LIMIT $1`).WithArgs(10).WillReturnRows(mockRows)
+
+	usernames, _, err := poolQueryFuncGetUsernames(ctx, mockPool, 10)
+	assert.Equal(t, connReset, err)
+	assert.Equal(t, []string{"johndoe"}, usernames)
+}
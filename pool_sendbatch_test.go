@@ -0,0 +1,128 @@
+package pgxpoolgo_test
+
+import (
+	"context"
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func poolSendBatchInsertUser(ctx context.Context, pool pgxpoolgo.Pool, username, email string) (uint32, error) {
+	var id uint32
+
+	batch := &pgx.Batch{}
+	batch.Queue(`INSERT INTO users (username, email) VALUES ($1, $2)`, username, email)
+	batch.Queue(`SELECT id FROM users WHERE username = $1`, username)
+
+	results := pool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	if _, err := results.Exec(); err != nil {
+		return id, err
+	}
+
+	if err := results.QueryRow().Scan(&id); err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+func TestPoolSendBatchInsertUser_OK(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockBatchResults := pgxpoolgo.NewMockBatchResults().
+		AddExec(pgxpoolgo.NewMockCommandTag("INSERT", 1)).
+		AddQueryRow(pgxpoolgo.NewMockRow([]string{"id"}).AddRow(uint32(1)))
+	mockPool.ExpectSendBatch().WillReturnBatchResults(mockBatchResults.Compose())
+
+	id, err := poolSendBatchInsertUser(ctx, mockPool, "johndoe", "johndoe@email.com")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), id)
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestPoolSendBatchInsertUser_NilRow(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockBatchResults := pgxpoolgo.NewMockBatchResults().
+		AddExec(pgxpoolgo.NewMockCommandTag("INSERT", 1)).
+		AddQueryRow(nil)
+	mockPool.ExpectSendBatch().WillReturnBatchResults(mockBatchResults.Compose())
+
+	_, err := poolSendBatchInsertUser(ctx, mockPool, "johndoe", "johndoe@email.com")
+	assert.EqualError(t, err, "batch result was scripted for QueryRow without a row")
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestPoolSendBatchInsertUser_On(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockBatchResults := pgxpoolgo.NewMockBatchResults().
+		AddExec(pgxpoolgo.NewMockCommandTag("INSERT", 1)).
+		AddQueryRow(pgxpoolgo.NewMockRow([]string{"id"}).AddRow(uint32(1)))
+	mockPool.On("SendBatch", ctx, mock.Anything).Return(mockBatchResults.Compose()).Once()
+
+	id, err := poolSendBatchInsertUser(ctx, mockPool, "johndoe", "johndoe@email.com")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), id)
+	assert.Equal(t, true, mockPool.AssertExpectations(t))
+}
+
+func TestPoolSendBatchInsertUser_WrongOrder(t *testing.T) {
+	ctx := context.Background()
+	mockPool := &pgxpoolgo.MockPool{}
+
+	mockBatchResults := pgxpoolgo.NewMockBatchResults().
+		AddQueryRow(pgxpoolgo.NewMockRow([]string{"id"}).AddRow(uint32(1))).
+		AddExec(pgxpoolgo.NewMockCommandTag("INSERT", 1)).
+		AddExec(pgxpoolgo.NewMockCommandTag("INSERT", 1))
+	mockPool.ExpectSendBatch().WillReturnBatchResults(mockBatchResults.Compose())
+
+	_, err := poolSendBatchInsertUser(ctx, mockPool, "johndoe", "johndoe@email.com")
+	assert.EqualError(t, err, "batch result 0 was scripted for QueryRow, but read with Exec")
+
+	err = mockPool.ExpectationsWereMet()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "batch results were closed after reading 1 of 2 queued queries")
+	assert.Contains(t, err.Error(), "3 batch results were scripted for 2 queued queries")
+}
+
+func TestPoolSendBatchInsertUser_Queued(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockBatchResults := pgxpoolgo.NewMockBatchResults().
+		AddExec(pgxpoolgo.NewMockCommandTag("INSERT", 1)).
+		AddQueryRow(pgxpoolgo.NewMockRow([]string{"id"}).AddRow(uint32(1)))
+	mockPool.ExpectSendBatch().
+		WithQueued(`insert into users (username, email) values ($1, $2)`, "johndoe", "johndoe@email.com").
+		WithQueued(`select id from users where username = $1`, "johndoe").
+		WillReturnBatchResults(mockBatchResults.Compose())
+
+	id, err := poolSendBatchInsertUser(ctx, mockPool, "johndoe", "johndoe@email.com")
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), id)
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestPoolSendBatchInsertUser_QueuedDiffers(t *testing.T) {
+	ctx := context.Background()
+	mockPool := &pgxpoolgo.MockPool{}
+
+	expectedBatch := &pgx.Batch{}
+	expectedBatch.Queue(`INSERT INTO users (username, email) VALUES ($1, $2)`, "johndoe", "johndoe@email.com")
+	expectedBatch.Queue(`SELECT id FROM users WHERE email = $1`, "johndoe@email.com")
+	mockPool.ExpectSendBatch().WithBatch(expectedBatch)
+
+	_, err := poolSendBatchInsertUser(ctx, mockPool, "johndoe", "johndoe@email.com")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "which does not match: queued query 1 differs")
+	assert.Contains(t, err.Error(), "expected: select id from users where email=$1")
+	assert.Contains(t, err.Error(), "actual:   select id from users where username=$1")
+}
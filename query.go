@@ -0,0 +1,149 @@
+package pgxpoolgo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+/*
+The codes below is based on `pgxmock` from `github.com/pashagolub/pgxmock`
+*/
+
+// QueryMatcher is an SQL query string matcher interface, which can be used to customize validation of SQL query
+// strings of the Expect methods.
+type QueryMatcher interface {
+	Match(expectedSQL, actualSQL string) error
+}
+
+// QueryMatcherFunc type is an adapter to allow the use of ordinary functions as QueryMatcher.
+type QueryMatcherFunc func(expectedSQL, actualSQL string) error
+
+// Match implements the QueryMatcher.
+func (f QueryMatcherFunc) Match(expectedSQL, actualSQL string) error {
+	return f(expectedSQL, actualSQL)
+}
+
+// QueryMatcherNormalized is the default SQL query matcher. It compares the expected and actual SQL after collapsing
+// whitespace and folding the case of everything outside quoted literals and identifiers.
+var QueryMatcherNormalized QueryMatcher = QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+	expect := normalizeQuery(expectedSQL)
+	actual := normalizeQuery(actualSQL)
+	if actual != expect {
+		return fmt.Errorf(`actual sql: "%s" does not equal to expected "%s"`, actual, expect)
+	}
+	return nil
+})
+
+// QueryMatcherEqual is the SQL query matcher which simply tries a case sensitive match of expected and actual SQL
+// strings without surrounding whitespace.
+var QueryMatcherEqual QueryMatcher = QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+	expect := strings.TrimSpace(expectedSQL)
+	actual := strings.TrimSpace(actualSQL)
+	if actual != expect {
+		return fmt.Errorf(`actual sql: "%s" does not equal to expected "%s"`, actual, expect)
+	}
+	return nil
+})
+
+// QueryMatcherRegexp is the SQL query matcher which parses expectedSQL to a regular expression and attempts to
+// match the actual SQL with collapsed whitespace.
+var QueryMatcherRegexp QueryMatcher = QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+	expect := stripQuery(expectedSQL)
+	actual := stripQuery(actualSQL)
+	re, err := regexp.Compile(expect)
+	if err != nil {
+		return err
+	}
+	if !re.MatchString(actual) {
+		return fmt.Errorf(`could not match actual sql: "%s" with expected regexp "%s"`, actual, re.String())
+	}
+	return nil
+})
+
+var spaceRe = regexp.MustCompile(`\s+`)
+
+// stripQuery strips out new lines and trims spaces.
+func stripQuery(q string) string {
+	return strings.TrimSpace(spaceRe.ReplaceAllString(q, " "))
+}
+
+// normalizeQuery collapses whitespace, drops whitespace around parentheses, commas and comparison operators, drops a
+// trailing semicolon and lower-cases everything outside single-quoted literals and double-quoted identifiers.
+func normalizeQuery(q string) string {
+	var b strings.Builder
+	var quote, last rune
+	space := false
+	for _, c := range strings.TrimSpace(q) {
+		if quote != 0 {
+			b.WriteRune(c)
+			last = c
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if unicode.IsSpace(c) {
+			space = true
+			continue
+		}
+		if space {
+			if b.Len() > 0 && !isQueryPunct(c) && !isQueryPunct(last) {
+				b.WriteByte(' ')
+			}
+			space = false
+		}
+		if c == '\'' || c == '"' {
+			quote = c
+		}
+		last = unicode.ToLower(c)
+		b.WriteRune(last)
+	}
+	return strings.TrimSpace(strings.TrimSuffix(b.String(), ";"))
+}
+
+func isQueryPunct(c rune) bool {
+	return strings.ContainsRune("(),=<>!", c)
+}
+
+// queryDiff renders a readable diff of the normalized expected and actual SQL, pointing at the first difference.
+func queryDiff(expectedSQL, actualSQL string) string {
+	expect := []rune(normalizeQuery(expectedSQL))
+	actual := []rune(normalizeQuery(actualSQL))
+	at := 0
+	for at < len(expect) && at < len(actual) && expect[at] == actual[at] {
+		at++
+	}
+	return fmt.Sprintf("    expected: %s\n    actual:   %s\n              %s^", string(expect), string(actual), strings.Repeat(" ", at))
+}
+
+// queryDistance returns the Levenshtein distance of the normalized expected and actual SQL.
+func queryDistance(expectedSQL, actualSQL string) int {
+	a := []rune(normalizeQuery(expectedSQL))
+	b := []rune(normalizeQuery(actualSQL))
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -1,11 +1,13 @@
 package pgxpoolgo
 
 import (
+	"errors"
 	"fmt"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
-	"reflect"
+	"sync"
 )
 
 /*
@@ -13,27 +15,37 @@ The codes below is based on `pgxmock` from `github.com/pashagolub/pgxmock`
 */
 
 type MockRows struct {
-	commandTag pgconn.CommandTag
-	defs       []pgproto3.FieldDescription
-	rows       [][]interface{}
-	index      int
-	scanErr    map[int]error
+	commandTag   pgconn.CommandTag
+	defs         []pgproto3.FieldDescription
+	nullable     []bool
+	rows         [][]interface{}
+	scanErr      map[int]error
+	rowErr       map[int]error
+	closeErr     error
+	errAfterNext bool
+	next         *MockRows
 }
 
 type rows struct {
-	rows  []*MockRows
-	index int
+	rows   []*MockRows
+	index  int
+	pos    int
+	closed bool
+	err    error
+	sql    string
+	mu     sync.Mutex
 }
 
 // NewMockRows mocks pgx.Rows.
 func NewMockRows(columns []string) *MockRows {
 	var coldefs []pgproto3.FieldDescription
 	for _, column := range columns {
-		coldefs = append(coldefs, pgproto3.FieldDescription{Name: []byte(column)})
+		coldefs = append(coldefs, NewMockColumn(column).def)
 	}
 	return &MockRows{
 		defs:    coldefs,
 		scanErr: make(map[int]error),
+		rowErr:  make(map[int]error),
 	}
 }
 
@@ -42,10 +54,43 @@ func (mr *MockRows) ScanError(rowIndex int, err error) *MockRows {
 	return mr
 }
 
+// RowError makes Next return false instead of reading the row at rowIndex, and Err return err, like a connection
+// failing in the middle of the result.
+func (mr *MockRows) RowError(rowIndex int, err error) *MockRows {
+	mr.rowErr[rowIndex] = err
+	return mr
+}
+
+// CloseError makes Err return err once the rows are closed, like an error reported by the server when the query
+// completes.
+func (mr *MockRows) CloseError(err error) *MockRows {
+	mr.closeErr = err
+	return mr
+}
+
+// ErrAfterNext makes Err report the errors of the rows only after Next returned false, as pgx does, instead of
+// reporting the scan error of the current row.
+func (mr *MockRows) ErrAfterNext() *MockRows {
+	mr.errAfterNext = true
+	return mr
+}
+
+// AddResultSet chains the rows of next after the rows of mr. Next moves to the rows of next once the rows of mr
+// are read, and FieldDescriptions and CommandTag then describe next.
+func (mr *MockRows) AddResultSet(next *MockRows) *MockRows {
+	last := mr
+	for last.next != nil {
+		last = last.next
+	}
+	last.next = next
+	return mr
+}
+
 func (mr *MockRows) AddRow(values ...interface{}) *MockRows {
 	if len(values) != len(mr.defs) {
 		panic("expected number of values to match number of columns")
 	}
+	inferColumns(mr.defs, mr.nullable, values)
 	newRow := make([]interface{}, len(mr.defs))
 	copy(newRow, values)
 	mr.rows = append(mr.rows, newRow)
@@ -58,95 +103,162 @@ func (mr *MockRows) AddCommandTag(tag pgconn.CommandTag) *MockRows {
 }
 
 func (mr *MockRows) Compose() pgx.Rows {
-	return &rows{rows: []*MockRows{mr}}
+	return ComposeRows(mr)
 }
 
-func (r *rows) Close() {}
+// ComposeRows composes the result sets into a single pgx.Rows, read one after another.
+func ComposeRows(sets ...*MockRows) pgx.Rows {
+	r := &rows{}
+	for _, set := range sets {
+		for ; set != nil; set = set.next {
+			r.rows = append(r.rows, set)
+		}
+	}
+	if len(r.rows) == 0 {
+		r.rows = []*MockRows{NewMockRows(nil)}
+	}
+	return r
+}
+
+func (r *rows) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.close()
+}
+
+// close closes the rows, with r.mu locked.
+func (r *rows) close() {
+	if r.closed {
+		return
+	}
+	r.closed = true
+	for _, set := range r.rows {
+		if set.closeErr != nil && r.err == nil {
+			r.err = set.closeErr
+		}
+	}
+}
 
 func (r *rows) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	currentRow := r.rows[r.index]
-	return currentRow.scanErr[currentRow.index-1]
+	if currentRow.errAfterNext && !r.closed {
+		return nil
+	}
+	if r.err != nil {
+		return r.err
+	}
+	if r.pos < 1 {
+		return nil
+	}
+	return currentRow.scanErr[r.pos-1]
 }
 
 func (r *rows) CommandTag() pgconn.CommandTag {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return r.rows[r.index].commandTag
 }
 
 func (r *rows) FieldDescriptions() []pgproto3.FieldDescription {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return r.rows[r.index].defs
 }
 
 func (r *rows) Next() bool {
-	currentRow := r.rows[r.index]
-	currentRow.index++
-	return currentRow.index <= len(currentRow.rows)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return false
+	}
+	for {
+		currentRow := r.rows[r.index]
+		if err := currentRow.rowErr[r.pos]; err != nil {
+			r.err = err
+			r.close()
+			return false
+		}
+		if r.pos < len(currentRow.rows) {
+			r.pos++
+			return true
+		}
+		if r.index+1 >= len(r.rows) {
+			r.close()
+			return false
+		}
+		r.index++
+		r.pos = 0
+	}
 }
 
 func (r *rows) Scan(dest ...interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	currentRow := r.rows[r.index]
-	if currentRow.scanErr[currentRow.index-1] != nil {
-		return currentRow.scanErr[currentRow.index-1]
-	}
-	if len(dest) != len(currentRow.defs) {
-		return fmt.Errorf("incorrect argument number %d for columns %d", len(dest), len(currentRow.defs))
+	if err := r.readable(); err != nil {
+		return err
 	}
-	for i, col := range currentRow.rows[currentRow.index-1] {
-		if dest[i] == nil {
-			continue
-		}
-		destVal := reflect.ValueOf(dest[i])
-		if destVal.Kind() != reflect.Ptr {
-			return fmt.Errorf("destination argument must be a pointer for column %s", currentRow.defs[i].Name)
-		}
-		if col == nil {
-			dest[i] = nil
-			continue
-		}
-		val := reflect.ValueOf(col)
-		if _, ok := dest[i].(*interface{}); ok || destVal.Elem().Kind() == val.Kind() {
-			if destElem := destVal.Elem(); destElem.CanSet() {
-				destElem.Set(val)
-			} else {
-				return fmt.Errorf("cannot set destination  value for column %s", string(currentRow.defs[i].Name))
-			}
-		} else {
-			scanner, ok := destVal.Interface().(interface{ Scan(interface{}) error })
-			if !ok {
-				return fmt.Errorf("destination kind '%v' not supported for value kind '%v' of column '%s'",
-					destVal.Elem().Kind(), val.Kind(), string(currentRow.defs[i].Name))
-			}
-			if err := scanner.Scan(val.Interface()); err != nil {
-				return fmt.Errorf("scanning value error for column '%s': %w", string(currentRow.defs[i].Name), err)
-			}
+	if err := currentRow.scanErr[r.pos-1]; err != nil {
+		if r.err == nil {
+			r.err = err
 		}
+		return err
 	}
-	return nil
+	return scanRow(currentRow.defs, currentRow.rows[r.pos-1], dest)
 }
 
 func (r *rows) Values() ([]interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	currentRow := r.rows[r.index]
-	return currentRow.rows[currentRow.index-1], currentRow.scanErr[currentRow.index-1]
+	if err := r.readable(); err != nil {
+		return nil, err
+	}
+	return currentRow.rows[r.pos-1], currentRow.scanErr[r.pos-1]
 }
 
 func (r *rows) RawValues() [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	currentRow := r.rows[r.index]
+	if r.readable() != nil {
+		return nil
+	}
+	ci := pgtype.NewConnInfo()
 	dest := make([][]byte, len(currentRow.defs))
-	for i, col := range currentRow.rows[currentRow.index-1] {
-		if b, ok := rawBytes(col); ok {
-			dest[i] = b
-			continue
-		}
-		dest[i] = col.([]byte)
+	for i, col := range currentRow.rows[r.pos-1] {
+		dest[i] = rawValue(ci, currentRow.defs[i], col)
 	}
 	return dest
 }
 
-func rawBytes(col interface{}) (_ []byte, ok bool) {
-	val, ok := col.([]byte)
-	if !ok || len(val) == 0 {
-		return nil, false
+// readable returns the error pgx returns when the rows are read after Close, or before Next. It is called with r.mu
+// locked.
+func (r *rows) readable() error {
+	if r.closed {
+		return errors.New("rows is closed")
+	}
+	if r.pos < 1 {
+		return fmt.Errorf("number of field descriptions must equal number of values, got %d and %d", len(r.rows[r.index].defs), 0)
+	}
+	return nil
+}
+
+// bind sets the SQL of the query the rows were returned for, to describe them in verify.
+func (r *rows) bind(sql string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sql = sql
+}
+
+// verify returns an error when the rows were not closed, which leaks the connection with pgx.
+func (r *rows) verify() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.closed {
+		return fmt.Errorf("rows of '%s' were not closed", stripQuery(r.sql))
 	}
-	b := make([]byte, len(val))
-	copy(b, val)
-	return b, true
+	return nil
 }
@@ -0,0 +1,158 @@
+package pgxpoolgo_test
+
+import (
+	"context"
+	"errors"
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func rowsReadNames(rows pgx.Rows) ([]string, error) {
+	var names []string
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return names, err
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+func TestRowsResultSets_OK(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	users := pgxpoolgo.NewMockRows([]string{"name"}).AddRow("johndoe").AddCommandTag(pgxpoolgo.NewMockCommandTag("SELECT", 1))
+	groups := pgxpoolgo.NewMockRows([]string{"name"}).AddRow("admin").AddRow("staff")
+	mockPool.ExpectQuery(`SELECT name FROM users; SELECT name FROM groups`).WillReturnRows(users, groups)
+
+	rows, err := mockPool.Query(ctx, `SELECT name FROM users; SELECT name FROM groups`)
+	assert.Nil(t, err)
+
+	names, err := rowsReadNames(rows)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"johndoe", "admin", "staff"}, names)
+}
+
+func TestRowsResultSets_Chained(t *testing.T) {
+	users := pgxpoolgo.NewMockRows([]string{"name"}).AddRow("johndoe").AddCommandTag(pgxpoolgo.NewMockCommandTag("SELECT", 1))
+	groups := pgxpoolgo.NewMockRows([]string{"name", "id"}).AddRow("admin", 1).AddCommandTag(pgxpoolgo.NewMockCommandTag("SELECT", 1))
+	rows := users.AddResultSet(groups).Compose()
+
+	assert.Equal(t, true, rows.Next())
+	assert.Equal(t, "name", string(rows.FieldDescriptions()[0].Name))
+	assert.Equal(t, true, rows.Next())
+	assert.Equal(t, 2, len(rows.FieldDescriptions()))
+	assert.Equal(t, false, rows.Next())
+	assert.Nil(t, rows.Err())
+}
+
+func TestRowsRowError_OK(t *testing.T) {
+	connReset := errors.New("connection reset by peer")
+	rows := pgxpoolgo.NewMockRows([]string{"name"}).
+		AddRow("johndoe").
+		AddRow("janedoe").
+		RowError(1, connReset).
+		Compose()
+
+	names, err := rowsReadNames(rows)
+	assert.Equal(t, connReset, err)
+	assert.Equal(t, []string{"johndoe"}, names)
+}
+
+func TestRowsCloseError_OK(t *testing.T) {
+	closeErr := errors.New("canceling statement due to statement timeout")
+	rows := pgxpoolgo.NewMockRows([]string{"name"}).AddRow("johndoe").CloseError(closeErr).Compose()
+
+	assert.Equal(t, true, rows.Next())
+	assert.Nil(t, rows.Err())
+	rows.Close()
+	assert.Equal(t, closeErr, rows.Err())
+}
+
+func TestRowsErrAfterNext_OK(t *testing.T) {
+	scanErr := errors.New("scan failed")
+	rows := pgxpoolgo.NewMockRows([]string{"name"}).
+		AddRow("johndoe").
+		AddRow("janedoe").
+		ScanError(0, scanErr).
+		ErrAfterNext().
+		Compose()
+
+	assert.Equal(t, true, rows.Next())
+	assert.Nil(t, rows.Err())
+	assert.Equal(t, scanErr, rows.Scan(new(string)))
+	assert.Nil(t, rows.Err())
+	assert.Equal(t, true, rows.Next())
+	assert.Equal(t, false, rows.Next())
+	assert.Equal(t, scanErr, rows.Err())
+}
+
+func TestRowsClose_Unclosed(t *testing.T) {
+	ctx := context.Background()
+	mockPool := &pgxpoolgo.MockPool{}
+
+	mockPool.ExpectQuery(`SELECT name FROM users`).WillReturnRows(pgxpoolgo.NewMockRows([]string{"name"}).AddRow("johndoe").AddRow("janedoe"))
+
+	rows, err := mockPool.Query(ctx, `SELECT name FROM users`)
+	assert.Nil(t, err)
+	assert.Equal(t, true, rows.Next())
+
+	err = mockPool.ExpectationsWereMet()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "MockPool: rows of 'SELECT name FROM users' were not closed")
+
+	rows.Close()
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestRowsClose_UnclosedOn(t *testing.T) {
+	ctx := context.Background()
+	mockPool := &pgxpoolgo.MockPool{}
+
+	mockPool.On("Query", ctx, `SELECT name FROM users`).Return(pgxpoolgo.NewMockRows([]string{"name"}).AddRow("johndoe").Compose(), nil)
+
+	_, err := mockPool.Query(ctx, `SELECT name FROM users`)
+	assert.Nil(t, err)
+	assert.NotNil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestRowsClose_ReadAfterClose(t *testing.T) {
+	rows := pgxpoolgo.NewMockRows([]string{"name"}).AddRow("johndoe").Compose()
+
+	assert.Equal(t, true, rows.Next())
+	rows.Close()
+	assert.Equal(t, false, rows.Next())
+	assert.EqualError(t, rows.Scan(new(string)), "rows is closed")
+	_, err := rows.Values()
+	assert.EqualError(t, err, "rows is closed")
+}
+
+func TestRowsRawValues_OK(t *testing.T) {
+	rows := pgxpoolgo.NewMockRowsWithColumns(
+		pgxpoolgo.NewMockColumn("id").OfType("int8"),
+		pgxpoolgo.NewMockColumn("name").OfType("text").WithFormat(pgtype.TextFormatCode),
+		pgxpoolgo.NewMockColumn("score").OfType("int4").WithFormat(pgtype.TextFormatCode),
+		pgxpoolgo.NewMockColumn("avatar"),
+		pgxpoolgo.NewMockColumn("note"),
+		pgxpoolgo.NewMockColumn("status"),
+	).AddRow(int64(1), "johndoe", 42, []byte{0xff}, nil, struct{ Active bool }{true}).Compose()
+
+	assert.Nil(t, rows.RawValues())
+	assert.Equal(t, true, rows.Next())
+	values := rows.RawValues()
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 1}, values[0])
+	assert.Equal(t, []byte("johndoe"), values[1])
+	assert.Equal(t, []byte("42"), values[2])
+	assert.Equal(t, []byte{0xff}, values[3])
+	assert.Nil(t, values[4])
+	assert.Equal(t, []byte("{true}"), values[5])
+}
@@ -0,0 +1,140 @@
+package pgxpoolgo
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgtype"
+	"reflect"
+)
+
+// scanRow scans the values of a mock row into dest the way pgx does: every value is encoded with the pgtype data
+// type of its Go type, and decoded into its destination by pgtype.ConnInfo.Scan, so the same conversions apply.
+func scanRow(defs []pgproto3.FieldDescription, values []interface{}, dest []interface{}) error {
+	if len(dest) != len(defs) {
+		return fmt.Errorf("incorrect argument number %d for columns %d", len(dest), len(defs))
+	}
+	ci := pgtype.NewConnInfo()
+	for i, col := range values {
+		if dest[i] == nil {
+			continue
+		}
+		destVal := reflect.ValueOf(dest[i])
+		if destVal.Kind() != reflect.Ptr {
+			return fmt.Errorf("destination argument must be a pointer for column %s", defs[i].Name)
+		}
+		var err error
+		if col == nil {
+			err = scanNull(ci, defs[i].DataTypeOID, dest[i])
+		} else {
+			err = scanValue(ci, col, dest[i])
+		}
+		if err != nil {
+			return fmt.Errorf("can't scan into dest[%d] for column '%s': %w", i, string(defs[i].Name), err)
+		}
+	}
+	return nil
+}
+
+// scanNull scans a NULL value of the column type oid into dest. As in pgx, pointer to pointer destinations are set
+// to nil, sql.Scanner destinations scan nil, pgtype destinations get the Null status, and destinations which cannot
+// hold NULL fail.
+func scanNull(ci *pgtype.ConnInfo, oid uint32, dest interface{}) error {
+	if d, ok := dest.(*interface{}); ok {
+		*d = nil
+		return nil
+	}
+	return ci.Scan(oid, pgtype.TextFormatCode, nil, dest)
+}
+
+func scanValue(ci *pgtype.ConnInfo, col interface{}, dest interface{}) error {
+	val := reflect.ValueOf(col)
+	destElem := reflect.ValueOf(dest).Elem()
+	if _, ok := dest.(*interface{}); ok || val.Type().AssignableTo(destElem.Type()) {
+		if !destElem.CanSet() {
+			return fmt.Errorf("cannot set destination value")
+		}
+		destElem.Set(val)
+		return nil
+	}
+
+	if dt, ok := ci.DataTypeForValue(col); ok {
+		formatCode, src, err := encodeValue(ci, dt, col, pgtype.BinaryFormatCode)
+		if err != nil {
+			return err
+		}
+		return ci.Scan(dt.OID, formatCode, src, dest)
+	}
+
+	if valuer, ok := col.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			return nil
+		}
+		return scanValue(ci, v, dest)
+	}
+
+	if val.Kind() == destElem.Kind() && val.Type().ConvertibleTo(destElem.Type()) {
+		destElem.Set(val.Convert(destElem.Type()))
+		return nil
+	}
+
+	if scanner, ok := dest.(sql.Scanner); ok {
+		return scanner.Scan(col)
+	}
+
+	return fmt.Errorf("destination kind '%v' not supported for value kind '%v'", destElem.Kind(), val.Kind())
+}
+
+// encodeValue encodes v with the data type dt in formatCode, or in the other format when dt does not support it.
+func encodeValue(ci *pgtype.ConnInfo, dt *pgtype.DataType, v interface{}, formatCode int16) (int16, []byte, error) {
+	value, ok := v.(pgtype.Value)
+	if !ok || reflect.TypeOf(v) != reflect.TypeOf(dt.Value) {
+		value = pgtype.NewValue(dt.Value)
+		if err := value.Set(v); err != nil {
+			return 0, nil, err
+		}
+	}
+	binaryEncoder, _ := value.(pgtype.BinaryEncoder)
+	textEncoder, _ := value.(pgtype.TextEncoder)
+	if binaryEncoder != nil && (formatCode == pgtype.BinaryFormatCode || textEncoder == nil) {
+		src, err := binaryEncoder.EncodeBinary(ci, nil)
+		return pgtype.BinaryFormatCode, src, err
+	}
+	if textEncoder != nil {
+		src, err := textEncoder.EncodeText(ci, nil)
+		return pgtype.TextFormatCode, src, err
+	}
+	return 0, nil, fmt.Errorf("cannot encode %T", v)
+}
+
+// rawValue encodes v the way the server sends the values of the column def: nil for NULL, and in the format of the
+// column otherwise, with the type of the column or the type of v. []byte values are returned as they are, and the
+// values pgtype cannot encode are formatted as text.
+func rawValue(ci *pgtype.ConnInfo, def pgproto3.FieldDescription, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []byte:
+		b := make([]byte, len(v))
+		copy(b, v)
+		return b
+	}
+	var dts []*pgtype.DataType
+	if dt, ok := ci.DataTypeForOID(def.DataTypeOID); ok {
+		dts = append(dts, dt)
+	}
+	if dt, ok := ci.DataTypeForValue(v); ok {
+		dts = append(dts, dt)
+	}
+	for _, dt := range dts {
+		if _, src, err := encodeValue(ci, dt, v, def.Format); err == nil {
+			return src
+		}
+	}
+	return []byte(fmt.Sprint(v))
+}
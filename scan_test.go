@@ -0,0 +1,97 @@
+package pgxpoolgo_test
+
+import (
+	"database/sql"
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type scanStatus string
+
+func TestScan_OK(t *testing.T) {
+	createdAt := time.Date(2022, 10, 1, 8, 30, 0, 0, time.UTC)
+	mockRows := pgxpoolgo.NewMockRows([]string{"id", "name", "created_at", "score", "status"}).
+		AddRow(1, "johndoe", createdAt, int32(7), "active").
+		Compose()
+
+	var id int64
+	var name []byte
+	var createdAtTz pgtype.Timestamptz
+	var score sql.NullInt64
+	var status scanStatus
+
+	assert.Equal(t, true, mockRows.Next())
+	err := mockRows.Scan(&id, &name, &createdAtTz, &score, &status)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), id)
+	assert.Equal(t, []byte("johndoe"), name)
+	assert.Equal(t, pgtype.Present, createdAtTz.Status)
+	assert.Equal(t, true, createdAtTz.Time.Equal(createdAt))
+	assert.Equal(t, sql.NullInt64{Int64: 7, Valid: true}, score)
+	assert.Equal(t, scanStatus("active"), status)
+}
+
+func TestScan_PointerToPointer(t *testing.T) {
+	mockRow := pgxpoolgo.NewMockRow([]string{"id", "name"}).AddRow(int64(1), "johndoe").Compose()
+
+	var id *int
+	var name *string
+
+	err := mockRow.Scan(&id, &name)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, *id)
+	assert.Equal(t, "johndoe", *name)
+}
+
+func TestScan_OutOfRange(t *testing.T) {
+	mockRow := pgxpoolgo.NewMockRow([]string{"id"}).AddRow(int64(1 << 40)).Compose()
+
+	var id int32
+
+	err := mockRow.Scan(&id)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "can't scan into dest[0] for column 'id'")
+}
+
+func TestScan_Unsupported(t *testing.T) {
+	mockRow := pgxpoolgo.NewMockRow([]string{"name"}).AddRow("johndoe").Compose()
+
+	var name time.Time
+
+	err := mockRow.Scan(&name)
+	assert.NotNil(t, err)
+}
+
+func TestScan_Null(t *testing.T) {
+	mockRow := pgxpoolgo.NewMockRow([]string{"name", "email", "age", "created_at", "note"}).
+		AddRow(nil, nil, nil, nil, nil).
+		Compose()
+
+	name := new(string)
+	email := sql.NullString{String: "johndoe@email.com", Valid: true}
+	age := pgtype.Int4{Int: 30, Status: pgtype.Present}
+	createdAt := &time.Time{}
+	var note interface{} = "note"
+
+	err := mockRow.Scan(&name, &email, &age, &createdAt, &note)
+	assert.Nil(t, err)
+	assert.Nil(t, name)
+	assert.Equal(t, sql.NullString{}, email)
+	assert.Equal(t, pgtype.Null, age.Status)
+	assert.Nil(t, createdAt)
+	assert.Nil(t, note)
+}
+
+func TestScan_NullNotNullable(t *testing.T) {
+	mockRows := pgxpoolgo.NewMockRows([]string{"name"}).AddRow(nil).Compose()
+
+	var name string
+
+	assert.Equal(t, true, mockRows.Next())
+	err := mockRows.Scan(&name)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "can't scan into dest[0] for column 'name'")
+}
@@ -0,0 +1,642 @@
+package pgxpoolgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/mock"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockServer is a PostgreSQL server listening on a local socket, which answers the queries of real pgx connections
+// from the expectations registered with ExpectExec and ExpectQuery, so the encoding, the statement cache and the
+// protocol handling of pgx are exercised. It speaks the simple and the extended query protocols. Transaction
+// statements, like BEGIN, COMMIT and ROLLBACK, are answered without expectations.
+//
+// With the extended protocol, the parameters of a query are described with the types of the arguments expected with
+// WithArgs, as text otherwise, and are decoded into the types of the expected arguments to be matched, or into the
+// Go values of their types for an Argument, like AnyArg. With the simple protocol, pgx sends the arguments inside the
+// SQL, which is matched as it is.
+type MockServer struct {
+	t        mockServerTestingT
+	mock     mock.Mock
+	listener net.Listener
+	mu       sync.Mutex
+	conns    map[net.Conn]bool
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+type serverConn struct {
+	server     *MockServer
+	conn       net.Conn
+	backend    *pgproto3.Backend
+	statements map[string]*serverStatement
+	portals    map[string]*serverPortal
+	txStatus   byte
+	skip       bool
+}
+
+type serverStatement struct {
+	sql       string
+	paramOIDs []uint32
+}
+
+type serverPortal struct {
+	statement     *serverStatement
+	params        [][]byte
+	paramFormats  []int16
+	resultFormats []int16
+}
+
+// serverBackendPID is the process ID the server reports in BackendKeyData.
+const serverBackendPID = 4242
+
+const (
+	errCodeInternalError           = "XX000"
+	errCodeProtocolViolation       = "08P01"
+	errCodeInvalidSQLStatementName = "26000"
+)
+
+// mockServerTestingT is the test a MockServer reports to.
+type mockServerTestingT interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewMockServer starts a MockServer, which is closed and whose expectations are asserted at the end of the test.
+// Unexpected queries fail with an ErrorResponse, and are reported to t as soon as they are received.
+func NewMockServer(t mockServerTestingT) *MockServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Errorf("can't start MockServer: %v", err)
+		t.FailNow()
+		return nil
+	}
+	s := &MockServer{t: t, listener: listener, conns: make(map[net.Conn]bool)}
+	s.mock.Test(t)
+	s.wg.Add(1)
+	go s.serve()
+	t.Cleanup(func() {
+		s.Close()
+		s.state().assertExpectations(t)
+	})
+	return s
+}
+
+func (s *MockServer) state() *mockState {
+	return stateOf(&s.mock, "MockServer")
+}
+
+// Addr returns the address the server listens on.
+func (s *MockServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// ConnString returns the connection string of the server, to be parsed by pgxpool.ParseConfig.
+func (s *MockServer) ConnString() string {
+	return fmt.Sprintf("postgres://postgres@%s/postgres?sslmode=disable", s.Addr())
+}
+
+// Close stops the server and closes its connections.
+func (s *MockServer) Close() {
+	_ = s.listener.Close()
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// MatchExpectationsInOrder sets whether the expectations must be matched in the order they were registered. They
+// are matched in order by default.
+func (s *MockServer) MatchExpectationsInOrder(b bool) {
+	s.state().matchInOrder(b)
+}
+
+// ExpectationsWereMet checks whether all the expectations were met and no unexpected query was received.
+func (s *MockServer) ExpectationsWereMet() error {
+	return s.state().expectationsWereMet()
+}
+
+// UseQueryMatcher sets the QueryMatcher of the expectations. QueryMatcherNormalized is used by default.
+func (s *MockServer) UseQueryMatcher(qm QueryMatcher) {
+	s.state().useQueryMatcher(qm)
+}
+
+// ExpectExec expects a query with the expected SQL, answered with a command tag and no rows.
+func (s *MockServer) ExpectExec(expectedSQL string) *ExpectedExec {
+	e := &ExpectedExec{}
+	e.expectSQL = expectedSQL
+	s.state().expect(e)
+	return e
+}
+
+// ExpectQuery expects a query with the expected SQL, answered with rows. Several result sets are sent as the
+// results of several statements with the simple protocol.
+func (s *MockServer) ExpectQuery(expectedSQL string) *ExpectedQuery {
+	e := &ExpectedQuery{}
+	e.expectSQL = expectedSQL
+	s.state().expect(e)
+	return e
+}
+
+func (s *MockServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.conns[conn] = true
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			c := &serverConn{
+				server:     s,
+				conn:       conn,
+				backend:    pgproto3.NewBackend(pgproto3.NewChunkReader(conn), conn),
+				statements: make(map[string]*serverStatement),
+				portals:    make(map[string]*serverPortal),
+				txStatus:   'I',
+			}
+			_ = c.serve()
+			_ = conn.Close()
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// candidate returns the expectation a query with sql is likely to match, to describe it before it is executed. The
+// query is answered by the expectation execute finds, which can be another one when queries run concurrently.
+func (s *MockServer) candidate(sql string) expectation {
+	st := s.state()
+	defer st.lock().Unlock()
+	for _, e := range st.group.expected {
+		if e.fulfilled() {
+			continue
+		}
+		if q, ok := e.(interface{ expectedSQL() string }); ok && st.matcher.Match(q.expectedSQL(), sql) == nil {
+			return e
+		}
+		if st.inOrder() {
+			return nil
+		}
+	}
+	return nil
+}
+
+// execute answers a query with the expectations, with rows for ExpectQuery and a command tag for ExpectExec. A query
+// no expectation was registered for fails, and is reported to the test.
+func (s *MockServer) execute(sql string, args []interface{}) ([]*MockRows, error) {
+	methods := []string{"Query", "Exec"}
+	if _, ok := s.candidate(sql).(*ExpectedExec); ok {
+		methods = []string{"Exec", "Query"}
+	}
+	callArgs := append([]interface{}{context.Background(), sql}, args...)
+	ret, ok := s.state().calledAs(methods, callArgs)
+	if !ok {
+		err := fmt.Errorf("MockServer: call to %s was not expected, no expectation was registered", &mockCall{method: methods[0], args: callArgs})
+		s.t.Errorf("%s", err)
+		return nil, err
+	}
+	if err := ret.Error(1); err != nil {
+		return nil, err
+	}
+	switch v := ret.Get(0).(type) {
+	case *rows:
+		return v.rows, nil
+	case pgconn.CommandTag:
+		return []*MockRows{NewMockRows(nil).AddCommandTag(v)}, nil
+	}
+	return []*MockRows{NewMockRows(nil)}, nil
+}
+
+// expectedArgs returns the arguments expected for a query with sql, if any.
+func (s *MockServer) expectedArgs(sql string) []interface{} {
+	switch e := s.candidate(sql).(type) {
+	case *ExpectedExec:
+		return e.args
+	case *ExpectedQuery:
+		return e.args
+	}
+	return nil
+}
+
+// describe returns the columns of the rows expected for a query with sql, or nil when it returns no rows.
+func (s *MockServer) describe(sql string) []pgproto3.FieldDescription {
+	e, ok := s.candidate(sql).(*ExpectedQuery)
+	if !ok || len(e.rows) == 0 || len(e.rows[0].defs) == 0 {
+		return nil
+	}
+	return e.rows[0].defs
+}
+
+func (c *serverConn) serve() error {
+	if err := c.startup(); err != nil {
+		return err
+	}
+	for {
+		msg, err := c.backend.Receive()
+		if err != nil {
+			return err
+		}
+		if _, ok := msg.(*pgproto3.Terminate); ok {
+			return nil
+		}
+		if err := c.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *serverConn) startup() error {
+	msg, err := c.backend.ReceiveStartupMessage()
+	if err != nil {
+		return err
+	}
+	switch msg.(type) {
+	case *pgproto3.SSLRequest, *pgproto3.GSSEncRequest:
+		if _, err := c.conn.Write([]byte("N")); err != nil {
+			return err
+		}
+		return c.startup()
+	case *pgproto3.StartupMessage:
+	default:
+		return fmt.Errorf("unsupported startup message %T", msg)
+	}
+	msgs := []pgproto3.BackendMessage{&pgproto3.AuthenticationOk{}}
+	for _, param := range [][2]string{
+		{"server_version", "14.0"},
+		{"server_encoding", "UTF8"},
+		{"client_encoding", "UTF8"},
+		{"DateStyle", "ISO, MDY"},
+		{"integer_datetimes", "on"},
+		{"standard_conforming_strings", "on"},
+		{"TimeZone", "UTC"},
+	} {
+		msgs = append(msgs, &pgproto3.ParameterStatus{Name: param[0], Value: param[1]})
+	}
+	msgs = append(msgs, &pgproto3.BackendKeyData{ProcessID: serverBackendPID}, &pgproto3.ReadyForQuery{TxStatus: 'I'})
+	return c.send(msgs...)
+}
+
+func (c *serverConn) send(msgs ...pgproto3.BackendMessage) error {
+	var buf []byte
+	for _, msg := range msgs {
+		buf = msg.Encode(buf)
+	}
+	_, err := c.conn.Write(buf)
+	return err
+}
+
+func (c *serverConn) handle(msg pgproto3.FrontendMessage) error {
+	if _, ok := msg.(*pgproto3.Sync); ok {
+		c.skip = false
+		return c.send(&pgproto3.ReadyForQuery{TxStatus: c.txStatus})
+	}
+	if c.skip {
+		return nil
+	}
+	switch msg := msg.(type) {
+	case *pgproto3.Query:
+		return c.simpleQuery(msg.String)
+	case *pgproto3.Parse:
+		return c.parse(msg)
+	case *pgproto3.Describe:
+		return c.describe(msg)
+	case *pgproto3.Bind:
+		return c.bind(msg)
+	case *pgproto3.Execute:
+		return c.executePortal(msg)
+	case *pgproto3.Close:
+		if msg.ObjectType == 'S' {
+			delete(c.statements, msg.Name)
+		} else {
+			delete(c.portals, msg.Name)
+		}
+		return c.send(&pgproto3.CloseComplete{})
+	case *pgproto3.Flush:
+		return nil
+	}
+	return c.fail(&pgconn.PgError{Severity: "ERROR", Code: errCodeProtocolViolation, Message: fmt.Sprintf("unsupported message %T", msg)})
+}
+
+// fail sends the error err, and skips the messages of the extended protocol until the next Sync.
+func (c *serverConn) fail(err error) error {
+	c.skip = true
+	if c.txStatus == 'T' {
+		c.txStatus = 'E'
+	}
+	return c.send(errorResponse(err))
+}
+
+func (c *serverConn) simpleQuery(sql string) error {
+	if strings.Trim(sql, " \t\r\n;") == "" {
+		return c.send(&pgproto3.EmptyQueryResponse{}, &pgproto3.ReadyForQuery{TxStatus: c.txStatus})
+	}
+	var msgs []pgproto3.BackendMessage
+	sets, err := c.run(sql, nil)
+	if err == nil {
+		msgs, err = encodeResults(sets, true, nil)
+	}
+	if err != nil {
+		if c.txStatus == 'T' {
+			c.txStatus = 'E'
+		}
+		msgs = append(msgs, errorResponse(err))
+	}
+	msgs = append(msgs, &pgproto3.ReadyForQuery{TxStatus: c.txStatus})
+	return c.send(msgs...)
+}
+
+// run answers a query with sql and args, handling the transaction statements and the failed transactions.
+func (c *serverConn) run(sql string, args []interface{}) ([]*MockRows, error) {
+	if tag, ok := c.transaction(sql); ok {
+		return []*MockRows{NewMockRows(nil).AddCommandTag(pgconn.CommandTag(tag))}, nil
+	}
+	if c.txStatus == 'E' {
+		return nil, errTxAborted
+	}
+	return c.server.execute(sql, args)
+}
+
+// transaction answers the transaction statements, returning their command tag.
+func (c *serverConn) transaction(sql string) (string, bool) {
+	words := strings.Fields(strings.ToLower(strings.Trim(sql, " \t\r\n;")))
+	if len(words) == 0 {
+		return "", false
+	}
+	switch words[0] {
+	case "begin", "start":
+		c.txStatus = 'T'
+		return "BEGIN", true
+	case "commit", "end":
+		if c.txStatus == 'E' {
+			c.txStatus = 'I'
+			return "ROLLBACK", true
+		}
+		c.txStatus = 'I'
+		return "COMMIT", true
+	case "rollback", "abort":
+		if len(words) > 1 && words[1] == "to" {
+			c.txStatus = 'T'
+			return "ROLLBACK", true
+		}
+		c.txStatus = 'I'
+		return "ROLLBACK", true
+	case "savepoint":
+		if c.txStatus == 'E' {
+			return "", false
+		}
+		return "SAVEPOINT", true
+	case "release":
+		if c.txStatus == 'E' {
+			return "", false
+		}
+		return "RELEASE", true
+	}
+	return "", false
+}
+
+func (c *serverConn) parse(msg *pgproto3.Parse) error {
+	st := &serverStatement{sql: msg.Query, paramOIDs: append([]uint32(nil), msg.ParameterOIDs...)}
+	ci := pgtype.NewConnInfo()
+	expected := c.server.expectedArgs(st.sql)
+	n := countParams(st.sql)
+	if len(expected) > n {
+		n = len(expected)
+	}
+	for i := 0; i < n; i++ {
+		if i < len(st.paramOIDs) && st.paramOIDs[i] != 0 {
+			continue
+		}
+		oid := uint32(pgtype.TextOID)
+		if i < len(expected) && expected[i] != nil {
+			if dt, ok := ci.DataTypeForValue(expected[i]); ok {
+				oid = dt.OID
+			}
+		}
+		if i < len(st.paramOIDs) {
+			st.paramOIDs[i] = oid
+		} else {
+			st.paramOIDs = append(st.paramOIDs, oid)
+		}
+	}
+	c.statements[msg.Name] = st
+	return c.send(&pgproto3.ParseComplete{})
+}
+
+func (c *serverConn) describe(msg *pgproto3.Describe) error {
+	if msg.ObjectType == 'S' {
+		st, ok := c.statements[msg.Name]
+		if !ok {
+			return c.fail(errUnknownStatement(msg.Name))
+		}
+		description := &pgproto3.ParameterDescription{ParameterOIDs: st.paramOIDs}
+		return c.send(description, rowDescription(c.server.describe(st.sql), nil))
+	}
+	portal, ok := c.portals[msg.Name]
+	if !ok {
+		return c.fail(&pgconn.PgError{Severity: "ERROR", Code: "34000", Message: fmt.Sprintf("portal \"%s\" does not exist", msg.Name)})
+	}
+	return c.send(rowDescription(c.server.describe(portal.statement.sql), portal.resultFormats))
+}
+
+func (c *serverConn) bind(msg *pgproto3.Bind) error {
+	st, ok := c.statements[msg.PreparedStatement]
+	if !ok {
+		return c.fail(errUnknownStatement(msg.PreparedStatement))
+	}
+	portal := &serverPortal{
+		statement:     st,
+		paramFormats:  append([]int16(nil), msg.ParameterFormatCodes...),
+		resultFormats: append([]int16(nil), msg.ResultFormatCodes...),
+	}
+	for _, param := range msg.Parameters {
+		if param != nil {
+			param = append([]byte{}, param...)
+		}
+		portal.params = append(portal.params, param)
+	}
+	c.portals[msg.DestinationPortal] = portal
+	return c.send(&pgproto3.BindComplete{})
+}
+
+func (c *serverConn) executePortal(msg *pgproto3.Execute) error {
+	portal, ok := c.portals[msg.Portal]
+	if !ok {
+		return c.fail(&pgconn.PgError{Severity: "ERROR", Code: "34000", Message: fmt.Sprintf("portal \"%s\" does not exist", msg.Portal)})
+	}
+	args, err := portal.args(c.server.expectedArgs(portal.statement.sql))
+	if err != nil {
+		return c.fail(err)
+	}
+	sets, err := c.run(portal.statement.sql, args)
+	if err != nil {
+		return c.fail(err)
+	}
+	msgs, err := encodeResults(sets[:1], false, portal.resultFormats)
+	if err != nil {
+		if err := c.send(msgs...); err != nil {
+			return err
+		}
+		return c.fail(err)
+	}
+	return c.send(msgs...)
+}
+
+// args decodes the parameters of the portal into the types of the expected arguments, or into the values of their
+// pgtype data type.
+func (p *serverPortal) args(expected []interface{}) ([]interface{}, error) {
+	ci := pgtype.NewConnInfo()
+	args := make([]interface{}, len(p.params))
+	for i, param := range p.params {
+		if param == nil {
+			continue
+		}
+		oid := uint32(pgtype.TextOID)
+		if i < len(p.statement.paramOIDs) {
+			oid = p.statement.paramOIDs[i]
+		}
+		format := formatCode(p.paramFormats, i)
+		if i < len(expected) && expected[i] != nil && !isArgument(expected[i]) {
+			dest := reflect.New(reflect.TypeOf(expected[i]))
+			if ci.Scan(oid, format, param, dest.Interface()) == nil {
+				args[i] = dest.Elem().Interface()
+				if t, ok := args[i].(time.Time); ok && t.Equal(expected[i].(time.Time)) {
+					args[i] = expected[i]
+				}
+				continue
+			}
+		}
+		dt, ok := ci.DataTypeForOID(oid)
+		if !ok {
+			args[i] = string(param)
+			continue
+		}
+		value := pgtype.NewValue(dt.Value)
+		var err error
+		if format == pgtype.BinaryFormatCode {
+			err = value.(pgtype.BinaryDecoder).DecodeBinary(ci, param)
+		} else {
+			err = value.(pgtype.TextDecoder).DecodeText(ci, param)
+		}
+		if err != nil {
+			return nil, &pgconn.PgError{Severity: "ERROR", Code: errCodeProtocolViolation, Message: fmt.Sprintf("can't decode parameter $%d: %s", i+1, err)}
+		}
+		args[i] = value.Get()
+	}
+	return args, nil
+}
+
+// formatCode returns the format code of the value i, from the format codes of a Bind message.
+func formatCode(codes []int16, i int) int16 {
+	switch {
+	case len(codes) == 0:
+		return pgtype.TextFormatCode
+	case len(codes) == 1:
+		return codes[0]
+	case i < len(codes):
+		return codes[i]
+	}
+	return pgtype.TextFormatCode
+}
+
+// rowDescription describes the columns defs, with the format codes of a Bind message, or NoData without columns.
+func rowDescription(defs []pgproto3.FieldDescription, formats []int16) pgproto3.BackendMessage {
+	if len(defs) == 0 {
+		return &pgproto3.NoData{}
+	}
+	fields := make([]pgproto3.FieldDescription, len(defs))
+	for i, def := range defs {
+		fields[i] = def
+		if fields[i].DataTypeOID == 0 {
+			fields[i].DataTypeOID = pgtype.TextOID
+		}
+		fields[i].Format = formatCode(formats, i)
+	}
+	return &pgproto3.RowDescription{Fields: fields}
+}
+
+// encodeResults encodes the result sets, with their row description when describe is set, as the simple protocol
+// sends them. It returns the messages encoded until a row error or close error of the rows, and the error.
+func encodeResults(sets []*MockRows, describe bool, formats []int16) ([]pgproto3.BackendMessage, error) {
+	ci := pgtype.NewConnInfo()
+	var msgs []pgproto3.BackendMessage
+	for _, set := range sets {
+		description := rowDescription(set.defs, formats)
+		if rd, ok := description.(*pgproto3.RowDescription); ok && describe {
+			msgs = append(msgs, rd)
+		}
+		for i, row := range set.rows {
+			if err := set.rowErr[i]; err != nil {
+				return msgs, err
+			}
+			values := make([][]byte, len(row))
+			for j, v := range row {
+				def := set.defs[j]
+				def.Format = formatCode(formats, j)
+				values[j] = rawValue(ci, def, v)
+			}
+			msgs = append(msgs, &pgproto3.DataRow{Values: values})
+		}
+		if err := set.rowErr[len(set.rows)]; err != nil {
+			return msgs, err
+		}
+		if set.closeErr != nil {
+			return msgs, set.closeErr
+		}
+		tag := set.commandTag
+		if tag == nil {
+			tag = pgconn.CommandTag(fmt.Sprintf("SELECT %d", len(set.rows)))
+		}
+		msgs = append(msgs, &pgproto3.CommandComplete{CommandTag: tag})
+	}
+	return msgs, nil
+}
+
+func errUnknownStatement(name string) error {
+	return &pgconn.PgError{Severity: "ERROR", Code: errCodeInvalidSQLStatementName, Message: fmt.Sprintf("prepared statement \"%s\" does not exist", name)}
+}
+
+// errorResponse returns the ErrorResponse of err, with the fields of a *pgconn.PgError.
+func errorResponse(err error) *pgproto3.ErrorResponse {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		pgErr = &pgconn.PgError{Code: errCodeInternalError, Message: err.Error()}
+	}
+	severity := pgErr.Severity
+	if severity == "" {
+		severity = "ERROR"
+	}
+	return &pgproto3.ErrorResponse{
+		Severity:       severity,
+		Code:           pgErr.Code,
+		Message:        pgErr.Message,
+		Detail:         pgErr.Detail,
+		Hint:           pgErr.Hint,
+		SchemaName:     pgErr.SchemaName,
+		TableName:      pgErr.TableName,
+		ColumnName:     pgErr.ColumnName,
+		ConstraintName: pgErr.ConstraintName,
+	}
+}
@@ -0,0 +1,199 @@
+package pgxpoolgo_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+type serverTestingT struct {
+	errs     []string
+	cleanups []func()
+}
+
+func (t *serverTestingT) Logf(format string, args ...interface{}) {}
+
+func (t *serverTestingT) Errorf(format string, args ...interface{}) {
+	t.errs = append(t.errs, fmt.Sprintf(format, args...))
+}
+
+func (t *serverTestingT) FailNow() {}
+
+func (t *serverTestingT) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *serverTestingT) cleanup() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func serverConnect(t *testing.T, server *pgxpoolgo.MockServer, simple bool) *pgxpool.Pool {
+	config, err := pgxpool.ParseConfig(server.ConnString())
+	assert.Nil(t, err)
+	config.ConnConfig.PreferSimpleProtocol = simple
+	pool, err := pgxpool.ConnectConfig(context.Background(), config)
+	assert.Nil(t, err)
+	return pool
+}
+
+func serverRenameUser(ctx context.Context, pool pgxpoolgo.Pool, id int64, name string) (time.Time, error) {
+	var updatedAt time.Time
+
+	err := pool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, `UPDATE users SET name = $1 WHERE id = $2 RETURNING updated_at`, name, id).Scan(&updatedAt)
+	})
+
+	return updatedAt, err
+}
+
+func TestMockServer_OK(t *testing.T) {
+	ctx := context.Background()
+	server := pgxpoolgo.NewMockServer(t)
+	createdAt := time.Date(2022, 10, 1, 8, 30, 0, 0, time.UTC)
+
+	server.ExpectExec(`INSERT INTO users (name, age) VALUES ($1, $2)`).WithArgs("johndoe", int64(30)).
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("INSERT", 1))
+	server.ExpectQuery(`SELECT id, name, created_at, tags FROM users WHERE age > $1`).WithArgs(18).
+		WillReturnRows(pgxpoolgo.NewMockRows([]string{"id", "name", "created_at", "tags"}).
+			AddRow(int64(1), "johndoe", createdAt, []string{"admin"}).
+			AddRow(int64(2), nil, createdAt, []string{}))
+
+	pool := serverConnect(t, server, false)
+	defer pool.Close()
+
+	tag, err := pool.Exec(ctx, `INSERT INTO users (name, age) VALUES ($1, $2)`, "johndoe", int64(30))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), tag.RowsAffected())
+
+	rows, err := pool.Query(ctx, `SELECT id, name, created_at, tags FROM users WHERE age > $1`, 18)
+	assert.Nil(t, err)
+	var names []*string
+	for rows.Next() {
+		var id int64
+		var name *string
+		var created time.Time
+		var tags []string
+		assert.Nil(t, rows.Scan(&id, &name, &created, &tags))
+		assert.Equal(t, true, created.Equal(createdAt))
+		names = append(names, name)
+	}
+	assert.Nil(t, rows.Err())
+	assert.Equal(t, "SELECT 2", rows.CommandTag().String())
+	assert.Equal(t, 2, len(names))
+	assert.Equal(t, "johndoe", *names[0])
+	assert.Nil(t, names[1])
+}
+
+func TestMockServer_Transaction(t *testing.T) {
+	ctx := context.Background()
+	server := pgxpoolgo.NewMockServer(t)
+	updatedAt := time.Date(2022, 10, 2, 9, 0, 0, 0, time.UTC)
+
+	server.ExpectQuery(`UPDATE users SET name = $1 WHERE id = $2 RETURNING updated_at`).WithArgs("janedoe", int64(1)).
+		WillReturnRows(pgxpoolgo.NewMockRows([]string{"updated_at"}).AddRow(updatedAt))
+	server.ExpectQuery(`UPDATE users SET name = $1 WHERE id = $2 RETURNING updated_at`).WithArgs("johndoe", int64(2)).
+		WillReturnError(&pgconn.PgError{Code: pgxpoolgo.ErrDBCodeDuplicateKey, Message: "duplicate key value violates unique constraint \"users_name_key\""})
+
+	pool := serverConnect(t, server, false)
+	defer pool.Close()
+
+	got, err := serverRenameUser(ctx, pool, 1, "janedoe")
+	assert.Nil(t, err)
+	assert.Equal(t, true, got.Equal(updatedAt))
+
+	_, err = serverRenameUser(ctx, pool, 2, "johndoe")
+	assert.NotNil(t, err)
+	assert.Equal(t, true, pgxpoolgo.ErrDB(err).IsDuplicateKey())
+}
+
+func TestMockServer_SimpleProtocol(t *testing.T) {
+	ctx := context.Background()
+	server := pgxpoolgo.NewMockServer(t)
+
+	server.ExpectQuery(`SELECT name FROM users WHERE id = 1`).
+		WillReturnRows(pgxpoolgo.NewMockRows([]string{"name"}).AddRow("johndoe"))
+
+	pool := serverConnect(t, server, true)
+	defer pool.Close()
+
+	var name string
+	err := pool.QueryRow(ctx, `SELECT name FROM users WHERE id = $1`, 1).Scan(&name)
+	assert.Nil(t, err)
+	assert.Equal(t, "johndoe", name)
+	assert.Nil(t, pool.Ping(ctx))
+}
+
+func TestMockServer_Unexpected(t *testing.T) {
+	ctx := context.Background()
+	mockT := &serverTestingT{}
+	server := pgxpoolgo.NewMockServer(mockT)
+
+	server.ExpectExec(`DELETE FROM users WHERE id = $1`).WithArgs(int64(1))
+
+	pool := serverConnect(t, server, false)
+
+	_, err := pool.Exec(ctx, `DELETE FROM users WHERE id = $1`, int64(2))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "MockServer: call to Exec 'DELETE FROM users WHERE id = $1' with args [2] was not expected")
+
+	pool.Close()
+	mockT.cleanup()
+	assert.Equal(t, 1, len(mockT.errs))
+	assert.Contains(t, mockT.errs[0], "there is a remaining expectation which was not matched")
+}
+
+func TestMockServer_NoExpectations(t *testing.T) {
+	ctx := context.Background()
+	mockT := &serverTestingT{}
+	server := pgxpoolgo.NewMockServer(mockT)
+
+	pool := serverConnect(t, server, false)
+
+	_, err := pool.Exec(ctx, `DELETE FROM users WHERE name = $1`, "johndoe")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "MockServer: call to Query 'DELETE FROM users WHERE name = $1' with args [johndoe] was not expected")
+	assert.Nil(t, pool.Ping(ctx))
+
+	pool.Close()
+	mockT.cleanup()
+	if assert.Equal(t, 1, len(mockT.errs)) {
+		assert.Contains(t, mockT.errs[0], "no expectation was registered")
+	}
+}
+
+func TestMockServer_CloseWhileConnecting(t *testing.T) {
+	server := pgxpoolgo.NewMockServer(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if conn, err := net.Dial("tcp", server.Addr()); err == nil {
+				_, _ = conn.Read(make([]byte, 1))
+				_ = conn.Close()
+			}
+		}()
+	}
+	closed := make(chan struct{})
+	go func() {
+		server.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return")
+	}
+	wg.Wait()
+}
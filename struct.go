@@ -0,0 +1,183 @@
+package pgxpoolgo
+
+import (
+	"fmt"
+	"github.com/jackc/pgtype"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// structField is a field of a struct mapped to a column by NewMockRowsFromStructs and NewMockRowFromStruct.
+type structField struct {
+	column    string
+	index     []int
+	omitEmpty bool
+}
+
+// NewMockRowsFromStructs mocks pgx.Rows with a row for every struct of structs, which is a slice of structs or of
+// pointers to structs. The columns are the exported fields of the struct, named by their `db` tag, or by their name
+// in snake_case. Fields tagged `db:"-"` are skipped, the fields of untagged embedded structs are columns of the
+// struct, and a column tagged with omitempty, like `db:"email,omitempty"`, is left out when it is empty in every row.
+// Nil pointer fields are NULL.
+func NewMockRowsFromStructs(structs interface{}) *MockRows {
+	v := reflect.ValueOf(structs)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		panic(fmt.Sprintf("expected a slice of structs, got %T", structs))
+	}
+	fields := structFields(v.Type().Elem())
+	values := make([][]interface{}, v.Len())
+	for i := range values {
+		values[i] = structValues(v.Index(i), fields)
+	}
+	columns, keep := structColumns(v.Type().Elem(), fields, values)
+	mr := NewMockRowsWithColumns(columns...)
+	for _, row := range values {
+		mr.AddRow(keepValues(row, keep)...)
+	}
+	return mr
+}
+
+// NewMockRowFromStruct mocks pgx.Row with the fields of the struct, or pointer to struct, v, mapped to columns the
+// same way NewMockRowsFromStructs does.
+func NewMockRowFromStruct(v interface{}) *MockRow {
+	rv := reflect.ValueOf(v)
+	fields := structFields(rv.Type())
+	values := [][]interface{}{structValues(rv, fields)}
+	columns, keep := structColumns(rv.Type(), fields, values)
+	return NewMockRowWithColumns(columns...).AddRow(keepValues(values[0], keep)...)
+}
+
+func structFields(t reflect.Type) []structField {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("expected a struct, got %s", t))
+	}
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, hasTag := f.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && !hasTag && ft.Kind() == reflect.Struct {
+			if !f.IsExported() {
+				continue
+			}
+			for _, embedded := range structFields(ft) {
+				embedded.index = append([]int{i}, embedded.index...)
+				fields = append(fields, embedded)
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = snakeCase(f.Name)
+		}
+		fields = append(fields, structField{
+			column:    name,
+			index:     []int{i},
+			omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+		})
+	}
+	return fields
+}
+
+// structValues returns the values of the fields of the struct v, dereferencing pointers, and nil for nil pointers.
+func structValues(v reflect.Value, fields []structField) []interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	values := make([]interface{}, len(fields))
+	for i, f := range fields {
+		values[i] = fieldValue(v, f.index)
+	}
+	return values
+}
+
+func fieldValue(v reflect.Value, index []int) interface{} {
+	for _, i := range index {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
+
+// structColumns returns the columns of the fields, typed from the types of the fields, and which of the fields are
+// kept, leaving out the omitempty fields empty in every row.
+func structColumns(t reflect.Type, fields []structField, values [][]interface{}) ([]*MockColumn, []bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	ci := pgtype.NewConnInfo()
+	var columns []*MockColumn
+	keep := make([]bool, len(fields))
+	for i, f := range fields {
+		keep[i] = !f.omitEmpty
+		for _, row := range values {
+			if row[i] != nil && !reflect.ValueOf(row[i]).IsZero() {
+				keep[i] = true
+			}
+		}
+		if !keep[i] {
+			continue
+		}
+		column := NewMockColumn(f.column)
+		ft := t.FieldByIndex(f.index).Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if dt, ok := ci.DataTypeForValue(reflect.Zero(ft).Interface()); ok {
+			column.WithOID(dt.OID)
+		}
+		columns = append(columns, column)
+	}
+	return columns, keep
+}
+
+func keepValues(values []interface{}, keep []bool) []interface{} {
+	var kept []interface{}
+	for i, v := range values {
+		if keep[i] {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// snakeCase converts a Go field name to snake_case, keeping initialisms together, like UserID to user_id.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
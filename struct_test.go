@@ -0,0 +1,75 @@
+package pgxpoolgo_test
+
+import (
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type StructAudit struct {
+	CreatedAt time.Time
+	UpdatedBy *string `db:"updated_by"`
+}
+
+type structUser struct {
+	ID       int64 `db:"id"`
+	Username string
+	Email    string `db:"email,omitempty"`
+	Password string `db:"-"`
+	HTTPRole string
+	StructAudit
+	internal string
+}
+
+func TestNewMockRowsFromStructs_OK(t *testing.T) {
+	admin := "admin"
+	createdAt := time.Date(2022, 10, 1, 8, 30, 0, 0, time.UTC)
+	mockRows := pgxpoolgo.NewMockRowsFromStructs([]*structUser{
+		{ID: 1, Username: "johndoe", Password: "secret", HTTPRole: "admin", StructAudit: StructAudit{CreatedAt: createdAt, UpdatedBy: &admin}},
+		{ID: 2, Username: "janedoe", HTTPRole: "staff", StructAudit: StructAudit{CreatedAt: createdAt}},
+	})
+	rows := mockRows.Compose()
+
+	var columns []string
+	for _, def := range rows.FieldDescriptions() {
+		columns = append(columns, string(def.Name))
+	}
+	assert.Equal(t, []string{"id", "username", "http_role", "created_at", "updated_by"}, columns)
+	assert.Equal(t, uint32(pgtype.TextOID), rows.FieldDescriptions()[4].DataTypeOID)
+
+	var id int64
+	var username, role string
+	var updatedBy *string
+	var at time.Time
+
+	assert.Equal(t, true, rows.Next())
+	assert.Nil(t, rows.Scan(&id, &username, &role, &at, &updatedBy))
+	assert.Equal(t, "admin", *updatedBy)
+	assert.Equal(t, true, rows.Next())
+	assert.Nil(t, rows.Scan(&id, &username, &role, &at, &updatedBy))
+	assert.Equal(t, int64(2), id)
+	assert.Equal(t, "janedoe", username)
+	assert.Nil(t, updatedBy)
+	assert.Equal(t, false, rows.Next())
+}
+
+func TestNewMockRowFromStruct_OK(t *testing.T) {
+	mockRow := pgxpoolgo.NewMockRowFromStruct(structUser{ID: 1, Username: "johndoe", Email: "johndoe@email.com"})
+
+	var id int64
+	var username, email, role string
+	var at time.Time
+	var updatedBy *string
+
+	err := mockRow.Compose().Scan(&id, &username, &email, &role, &at, &updatedBy)
+	assert.Nil(t, err)
+	assert.Equal(t, "johndoe@email.com", email)
+}
+
+func TestNewMockRowsFromStructs_NotSlice(t *testing.T) {
+	assert.Panics(t, func() {
+		pgxpoolgo.NewMockRowsFromStructs(structUser{})
+	})
+}
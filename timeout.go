@@ -0,0 +1,78 @@
+package pgxpoolgo
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgconn"
+	"net"
+)
+
+// contextError is the error of a network call interrupted because its context is done. pgconn reports it as a
+// timeout.
+type contextError struct {
+	err error
+}
+
+func (e *contextError) Error() string {
+	return e.err.Error()
+}
+
+func (e *contextError) Timeout() bool {
+	return true
+}
+
+func (e *contextError) Temporary() bool {
+	return false
+}
+
+func (e *contextError) Unwrap() error {
+	return e.err
+}
+
+// callContext returns the context a mocked call was made with, which is its first argument.
+func callContext(args []interface{}) context.Context {
+	if len(args) > 0 {
+		if ctx, ok := args[0].(context.Context); ok && ctx != nil {
+			return ctx
+		}
+	}
+	return context.Background()
+}
+
+// timeoutError returns the error of a call interrupted because ctx is done, wrapped the way pgconn wraps it, so
+// pgconn.Timeout reports it and errors.Is finds context.Canceled or context.DeadlineExceeded. The wrapping error of
+// pgconn being unexported, it is taken from a connection whose dial is interrupted by ctx.
+func timeoutError(ctx context.Context) error {
+	config, err := pgconn.ParseConfig("host=127.0.0.1 sslmode=disable")
+	if err != nil {
+		return ctx.Err()
+	}
+	config.LookupFunc = func(_ context.Context, host string) ([]string, error) {
+		return []string{host}, nil
+	}
+	config.DialFunc = func(_ context.Context, _, _ string) (net.Conn, error) {
+		return nil, &contextError{err: ctx.Err()}
+	}
+	_, err = pgconn.ConnectConfig(context.Background(), config)
+	if timeout := errors.Unwrap(err); pgconn.Timeout(timeout) {
+		return timeout
+	}
+	return ctx.Err()
+}
+
+// contextDoneError returns the error of a call made with a ctx which is already done, the one pgconn returns before
+// sending anything, like "timeout: context already done: context canceled". It is taken from a connection which is
+// never written to.
+func contextDoneError(ctx context.Context) error {
+	client, server := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+	conn, err := pgconn.Construct(&pgconn.HijackedConn{Conn: client})
+	if err != nil {
+		return timeoutError(ctx)
+	}
+	if _, err = conn.Exec(ctx, "").ReadAll(); pgconn.Timeout(err) {
+		return err
+	}
+	return timeoutError(ctx)
+}
@@ -0,0 +1,89 @@
+package pgxpoolgo_test
+
+import (
+	"context"
+	"errors"
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func timeoutGetUserName(ctx context.Context, pool pgxpoolgo.Pool, id int) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	var name string
+	err := pool.QueryRow(ctx, `SELECT name FROM users WHERE id = $1`, id).Scan(&name)
+	return name, err
+}
+
+func TestTimeout_OK(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockPool.ExpectQueryRow(`SELECT name FROM users WHERE id = $1`).WithArgs(1).
+		WillReturnRow(pgxpoolgo.NewMockRow([]string{"name"}).AddRow("johndoe")).
+		WillDelayFor(5 * time.Millisecond)
+
+	start := time.Now()
+	name, err := timeoutGetUserName(ctx, mockPool, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "johndoe", name)
+	assert.Equal(t, true, time.Since(start) >= 5*time.Millisecond)
+}
+
+func TestTimeout_DeadlineExceeded(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockPool.ExpectQueryRow(`SELECT name FROM users WHERE id = $1`).WithArgs(1).
+		WillReturnRow(pgxpoolgo.NewMockRow([]string{"name"}).AddRow("johndoe")).
+		WillDelayFor(time.Minute)
+
+	start := time.Now()
+	_, err := timeoutGetUserName(ctx, mockPool, 1)
+	assert.NotNil(t, err)
+	assert.Equal(t, true, time.Since(start) < time.Second)
+	assert.Equal(t, true, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, true, pgconn.Timeout(err))
+	assert.Equal(t, "timeout: context deadline exceeded", err.Error())
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestTimeout_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mockPool := pgxpoolgo.NewMockPool(t)
+	mockTx := &pgxpoolgo.MockTx{}
+
+	mockPool.ExpectBegin().WillReturnTx(mockTx)
+	mockTx.ExpectExec(`UPDATE users SET active = false`).WillDelayFor(time.Minute)
+	mockTx.ExpectRollback()
+
+	tx, err := mockPool.Begin(ctx)
+	assert.Nil(t, err)
+	time.AfterFunc(5*time.Millisecond, cancel)
+	_, err = tx.Exec(ctx, `UPDATE users SET active = false`)
+	assert.Equal(t, true, errors.Is(err, context.Canceled))
+	assert.Equal(t, true, pgconn.Timeout(err))
+	_, err = tx.Exec(ctx, `UPDATE users SET active = false`)
+	assert.Contains(t, err.Error(), "current transaction is aborted")
+	assert.Nil(t, tx.Rollback(context.Background()))
+	assert.Equal(t, pgx.ErrTxClosed, tx.Rollback(context.Background()))
+}
+
+func TestTimeout_AlreadyDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mockPool := pgxpoolgo.NewMockPool(t)
+
+	mockPool.ExpectExec(`UPDATE users SET active = false`).WillReturnResult(pgxpoolgo.NewMockCommandTag("UPDATE", 1))
+
+	cancel()
+	_, err := mockPool.Exec(ctx, `UPDATE users SET active = false`)
+	assert.NotNil(t, err)
+	assert.Equal(t, true, errors.Is(err, context.Canceled))
+	assert.Equal(t, true, pgconn.Timeout(err))
+	assert.Equal(t, "timeout: context already done: context canceled", err.Error())
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
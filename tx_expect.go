@@ -0,0 +1,234 @@
+package pgxpoolgo
+
+import (
+	"errors"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/mock"
+)
+
+// txStatus is the status of the transaction of a MockTx.
+type txStatus int
+
+const (
+	txActive txStatus = iota
+	txCommitted
+	txRolledBack
+	txFailed
+)
+
+// errTxAborted is the error PostgreSQL returns for the statements of a transaction after one of them failed.
+var errTxAborted = &pgconn.PgError{
+	Severity: "ERROR",
+	Code:     "25P02",
+	Message:  "current transaction is aborted, commands ignored until end of transaction block",
+}
+
+func (_m *MockTx) state() *mockState {
+	return stateOf(&_m.Mock, "MockTx")
+}
+
+// Called answers a mocked call from the expectations registered with the Expect methods, and falls back to the
+// expectations registered with On. Expectations can be registered with the Expect methods while other goroutines call
+// the mock, but On must be called before, testify giving no safe way to read them concurrently.
+func (_m *MockTx) Called(arguments ...interface{}) mock.Arguments {
+	method := calledMethod()
+	leave := _m.state().enter()
+	defer leave()
+	if ret, ok := _m.state().txCalled(method); ok {
+		return ret
+	}
+	ret, ok := _m.state().called(method, arguments)
+	if !ok {
+		ret = _m.Mock.MethodCalled(method, arguments...)
+	}
+	_m.state().returned(method, arguments, ret)
+	return _m.state().txReturned(method, ret)
+}
+
+// AssertExpectations asserts that everything specified with On and Return, and every expectation registered with
+// the Expect methods, was in fact called as expected.
+func (_m *MockTx) AssertExpectations(t mock.TestingT) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	ok := _m.Mock.AssertExpectations(t)
+	return _m.state().assertExpectations(t) && ok
+}
+
+// MatchExpectationsInOrder sets whether the calls of the mock, and of the mocks it hands out unless they are set
+// otherwise, must match the expectations in the order they were registered. The mock which handed it out, and the
+// other mocks that one handed out, are not affected. They are matched in order by default.
+func (_m *MockTx) MatchExpectationsInOrder(b bool) {
+	_m.state().matchInOrder(b)
+}
+
+// ExpectationsWereMet checks whether all the expectations registered with the Expect methods, on the mock and on
+// every mock it handed out, were met and no unexpected call was made.
+func (_m *MockTx) ExpectationsWereMet() error {
+	return _m.state().expectationsWereMet()
+}
+
+// UseQueryMatcher sets the QueryMatcher of the Expect methods. QueryMatcherNormalized is used by default.
+func (_m *MockTx) UseQueryMatcher(qm QueryMatcher) {
+	_m.state().useQueryMatcher(qm)
+}
+
+// ExpectExec expects Tx.Exec to be called with the expected SQL.
+func (_m *MockTx) ExpectExec(expectedSQL string) *ExpectedExec {
+	e := &ExpectedExec{}
+	e.expectSQL = expectedSQL
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectQuery expects Tx.Query to be called with the expected SQL.
+func (_m *MockTx) ExpectQuery(expectedSQL string) *ExpectedQuery {
+	e := &ExpectedQuery{}
+	e.expectSQL = expectedSQL
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectQueryRow expects Tx.QueryRow to be called with the expected SQL.
+func (_m *MockTx) ExpectQueryRow(expectedSQL string) *ExpectedQueryRow {
+	e := &ExpectedQueryRow{}
+	e.expectSQL = expectedSQL
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectQueryFunc expects Tx.QueryFunc to be called with the expected SQL.
+func (_m *MockTx) ExpectQueryFunc(expectedSQL string) *ExpectedQueryFunc {
+	e := &ExpectedQueryFunc{}
+	e.expectSQL = expectedSQL
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectSendBatch expects Tx.SendBatch to be called.
+func (_m *MockTx) ExpectSendBatch() *ExpectedSendBatch {
+	e := &ExpectedSendBatch{}
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectCopyFrom expects Tx.CopyFrom to be called with the expected table name and column names.
+func (_m *MockTx) ExpectCopyFrom(tableName pgx.Identifier, columnNames []string) *ExpectedCopyFrom {
+	e := &ExpectedCopyFrom{tableName: tableName, columns: columnNames}
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectPrepare expects Tx.Prepare to be called with the statement name and the expected SQL. Once prepared, the
+// statement can be executed with its name as SQL, which is matched by its SQL.
+func (_m *MockTx) ExpectPrepare(name, expectedSQL string) *ExpectedPrepare {
+	e := &ExpectedPrepare{name: name}
+	e.expectSQL = expectedSQL
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectBegin expects Tx.Begin or Tx.BeginFunc to be called, which starts a pseudo nested transaction. BeginFunc
+// runs its function with the nested MockTx, which is then committed, or rolled back when the function fails.
+func (_m *MockTx) ExpectBegin() *ExpectedBegin {
+	e := &ExpectedBegin{}
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectCommit expects Tx.Commit to be called.
+func (_m *MockTx) ExpectCommit() *ExpectedCommit {
+	e := &ExpectedCommit{}
+	_m.state().expect(e)
+	return e
+}
+
+// ExpectRollback expects Tx.Rollback to be called.
+func (_m *MockTx) ExpectRollback() *ExpectedRollback {
+	e := &ExpectedRollback{}
+	_m.state().expect(e)
+	return e
+}
+
+// txCalled answers a call made on a transaction which is not active the way pgx does: every call fails with
+// pgx.ErrTxClosed once the transaction is committed or rolled back, and the statements of a failed transaction fail
+// with errTxAborted. It returns false when the call should be answered by the expectations, or by testify when the
+// mock does not track its transaction.
+func (s *mockState) txCalled(method string) (mock.Arguments, bool) {
+	group := s.lock()
+	status, tracked := s.tx, s.tracksTx()
+	group.Unlock()
+	if !tracked {
+		return nil, false
+	}
+	switch status {
+	case txCommitted, txRolledBack:
+		return errorArguments(method, pgx.ErrTxClosed)
+	case txFailed:
+		if method == "Commit" || method == "Rollback" {
+			return nil, false
+		}
+		return errorArguments(method, errTxAborted)
+	}
+	return nil, false
+}
+
+// txReturned moves the transaction to its next status according to what the call returned. A commit of a failed
+// transaction rolls it back, and fails with pgx.ErrTxCommitRollback.
+func (s *mockState) txReturned(method string, ret mock.Arguments) mock.Arguments {
+	defer s.lock().Unlock()
+	if !s.tracksTx() {
+		return ret
+	}
+	err := returnedError(ret)
+	switch method {
+	case "Commit":
+		if s.tx == txFailed && err == nil {
+			ret = mock.Arguments{pgx.ErrTxCommitRollback}
+		}
+		if s.tx == txFailed || err != nil {
+			s.tx = txRolledBack
+		} else {
+			s.tx = txCommitted
+		}
+	case "Rollback":
+		s.tx = txRolledBack
+	case "Exec", "Query", "QueryRow", "QueryFunc", "SendBatch", "CopyFrom", "Prepare":
+		if err != nil {
+			s.tx = txFailed
+		}
+	}
+	return ret
+}
+
+// tracksTx reports whether the mock follows the status of its transaction, which is the case when it was handed out
+// by an expectation or has expectations registered with the Expect methods. A mock only set with On is left to
+// testify, as it was before the Expect methods. It is called with the group locked.
+func (s *mockState) tracksTx() bool {
+	return s.parent != nil || s.expects()
+}
+
+// verify returns an error when the transaction of the mock was neither committed nor rolled back. It is called by
+// ExpectationsWereMet of the mock which started the transaction, with the group of that mock locked, which is the
+// group of the transaction mock once an expectation handed it out.
+func (s *mockState) verify() error {
+	if s.parent == nil {
+		group := s.lock()
+		status, tracked := s.tx, s.tracksTx()
+		group.Unlock()
+		if !tracked {
+			return nil
+		}
+		return txVerify(status)
+	}
+	return txVerify(s.tx)
+}
+
+func txVerify(status txStatus) error {
+	if status == txActive || status == txFailed {
+		return errors.New("transaction was neither committed nor rolled back")
+	}
+	return nil
+}
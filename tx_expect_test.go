@@ -0,0 +1,200 @@
+package pgxpoolgo_test
+
+import (
+	"context"
+	"errors"
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func txExpectTransferBalance(ctx context.Context, pool pgxpoolgo.Pool, from, to string, amount int64) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, `UPDATE accounts SET balance = balance - $1 WHERE username = $2`, amount, from); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, `UPDATE accounts SET balance = balance + $1 WHERE username = $2`, amount, to); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func TestTxExpectTransferBalance_OK(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+	mockTx := pgxpoolgo.NewMockTx(t)
+
+	mockPool.ExpectBegin().WillReturnTx(mockTx)
+	mockTx.ExpectExec(`UPDATE accounts SET balance = balance - $1 WHERE username = $2`).WithArgs(int64(10), "johndoe").
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("UPDATE", 1))
+	mockTx.ExpectExec(`UPDATE accounts SET balance = balance + $1 WHERE username = $2`).WithArgs(int64(10), "janedoe").
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("UPDATE", 1))
+	mockTx.ExpectCommit()
+
+	err := txExpectTransferBalance(ctx, mockPool, "johndoe", "janedoe", 10)
+	assert.Nil(t, err)
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestTxExpectTransferBalance_OutOfOrder(t *testing.T) {
+	ctx := context.Background()
+	mockPool := &pgxpoolgo.MockPool{}
+	mockTx := &pgxpoolgo.MockTx{}
+
+	mockPool.ExpectBegin().WillReturnTx(mockTx)
+	mockTx.ExpectExec(`UPDATE accounts SET balance = balance + $1 WHERE username = $2`).WithArgs(int64(10), "janedoe")
+	mockTx.ExpectExec(`UPDATE accounts SET balance = balance - $1 WHERE username = $2`).WithArgs(int64(10), "johndoe")
+	mockTx.ExpectRollback()
+
+	err := txExpectTransferBalance(ctx, mockPool, "johndoe", "janedoe", 10)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "MockTx: call to Exec 'UPDATE accounts SET balance = balance - $1 WHERE username = $2' with args [10 johndoe] was not expected, next expectation is: MockTx: ExpectedExec")
+	assert.NotNil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestTxExpectTransferBalance_Unordered(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+	mockTx := pgxpoolgo.NewMockTx(t)
+	mockPool.MatchExpectationsInOrder(false)
+
+	mockTx.ExpectCommit()
+	mockTx.ExpectExec(`UPDATE accounts SET balance = balance + $1 WHERE username = $2`).WithArgs(int64(10), "janedoe").
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("UPDATE", 1))
+	mockTx.ExpectExec(`UPDATE accounts SET balance = balance - $1 WHERE username = $2`).WithArgs(int64(10), "johndoe").
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("UPDATE", 1))
+	mockPool.ExpectBegin().WillReturnTx(mockTx)
+
+	err := txExpectTransferBalance(ctx, mockPool, "johndoe", "janedoe", 10)
+	assert.Nil(t, err)
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestTxExpectTransferBalance_NotCommitted(t *testing.T) {
+	ctx := context.Background()
+	mockPool := &pgxpoolgo.MockPool{}
+	mockTx := &pgxpoolgo.MockTx{}
+
+	mockPool.ExpectBegin().WillReturnTx(mockTx)
+	mockTx.ExpectExec(`UPDATE accounts SET balance = balance - $1 WHERE username = $2`).WithArgs(int64(10), "johndoe").
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("UPDATE", 1))
+	mockTx.ExpectExec(`UPDATE accounts SET balance = balance + $1 WHERE username = $2`).WithArgs(int64(10), "janedoe").
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("UPDATE", 1))
+	mockTx.ExpectCommit()
+	mockTx.ExpectExec(`DELETE FROM transfers`)
+
+	err := txExpectTransferBalance(ctx, mockPool, "johndoe", "janedoe", 10)
+	assert.Nil(t, err)
+	err = mockPool.ExpectationsWereMet()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "MockTx: there is a remaining expectation which was not matched: ExpectedExec")
+}
+
+func TestTxExpectLifecycle_Closed(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+	mockTx := pgxpoolgo.NewMockTx(t)
+
+	mockPool.ExpectBegin().WillReturnTx(mockTx)
+	mockTx.ExpectCommit()
+
+	tx, err := mockPool.Begin(ctx)
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Commit(ctx))
+	assert.Equal(t, pgx.ErrTxClosed, tx.Rollback(ctx))
+	_, err = tx.Exec(ctx, `DELETE FROM users`)
+	assert.Equal(t, pgx.ErrTxClosed, err)
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestTxExpectLifecycle_Aborted(t *testing.T) {
+	ctx := context.Background()
+	mockPool := pgxpoolgo.NewMockPool(t)
+	mockTx := pgxpoolgo.NewMockTx(t)
+
+	mockPool.ExpectBegin().WillReturnTx(mockTx)
+	mockTx.ExpectExec(`DELETE FROM users`).WillReturnError(errors.New("permission denied for table users"))
+	mockTx.ExpectCommit()
+
+	tx, err := mockPool.Begin(ctx)
+	assert.Nil(t, err)
+	_, err = tx.Exec(ctx, `DELETE FROM users`)
+	assert.NotNil(t, err)
+	_, err = tx.Exec(ctx, `DELETE FROM profiles`)
+	var pgErr *pgconn.PgError
+	assert.Equal(t, true, errors.As(err, &pgErr))
+	assert.Equal(t, "25P02", pgErr.Code)
+	assert.Contains(t, pgErr.Message, "current transaction is aborted")
+	assert.Equal(t, pgx.ErrTxCommitRollback, tx.Commit(ctx))
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestTxExpectLifecycle_Unfinished(t *testing.T) {
+	ctx := context.Background()
+	mockPool := &pgxpoolgo.MockPool{}
+	mockTx := &pgxpoolgo.MockTx{}
+
+	mockPool.ExpectBegin().WillReturnTx(mockTx)
+
+	_, err := mockPool.Begin(ctx)
+	assert.Nil(t, err)
+	err = mockPool.ExpectationsWereMet()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "MockPool: transaction was neither committed nor rolled back")
+}
+
+func TestTxExpectLifecycle_OnOnly(t *testing.T) {
+	ctx := context.Background()
+	mockPool := &pgxpoolgo.MockPool{}
+	mockTx := &pgxpoolgo.MockTx{}
+
+	mockPool.On("Begin", ctx).Return(mockTx, nil)
+	mockTx.On("Exec", ctx, `DELETE FROM users`).Return(nil, errors.New("permission denied for table users"))
+	mockTx.On("Exec", ctx, `DELETE FROM profiles`).Return(pgconn.CommandTag("DELETE 1"), nil)
+	mockTx.On("Commit", ctx).Return(nil)
+
+	tx, err := mockPool.Begin(ctx)
+	assert.Nil(t, err)
+	_, err = tx.Exec(ctx, `DELETE FROM users`)
+	assert.NotNil(t, err)
+	tag, err := tx.Exec(ctx, `DELETE FROM profiles`)
+	assert.Nil(t, err)
+	assert.Equal(t, "DELETE 1", tag.String())
+	assert.Nil(t, tx.Commit(ctx))
+	mockPool.AssertExpectations(t)
+	mockTx.AssertExpectations(t)
+	assert.Nil(t, mockPool.ExpectationsWereMet())
+}
+
+func TestTxExpectTransferBalance_UnorderedTx(t *testing.T) {
+	ctx := context.Background()
+	mockPool := &pgxpoolgo.MockPool{}
+	mockTx := &pgxpoolgo.MockTx{}
+	mockTx.MatchExpectationsInOrder(false)
+
+	mockPool.ExpectBegin().WillReturnTx(mockTx)
+	mockTx.ExpectCommit()
+	mockTx.ExpectExec(`UPDATE accounts SET balance = balance + $1 WHERE username = $2`).WithArgs(int64(10), "janedoe").
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("UPDATE", 1))
+	mockTx.ExpectExec(`UPDATE accounts SET balance = balance - $1 WHERE username = $2`).WithArgs(int64(10), "johndoe").
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("UPDATE", 1))
+	mockPool.ExpectExec(`DELETE FROM transfers`)
+	mockPool.ExpectExec(`VACUUM transfers`)
+
+	err := txExpectTransferBalance(ctx, mockPool, "johndoe", "janedoe", 10)
+	assert.Nil(t, err)
+
+	_, err = mockPool.Exec(ctx, `VACUUM transfers`)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "MockPool: call to Exec 'VACUUM transfers' with args [] was not expected, next expectation is: MockPool: ExpectedExec")
+}
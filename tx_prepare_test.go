@@ -0,0 +1,67 @@
+package pgxpoolgo_test
+
+import (
+	"context"
+	"github.com/dalikewara/pgxpoolgo"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func txPrepareDeactivateUsers(ctx context.Context, tx pgx.Tx, usernames []string) error {
+	sd, err := tx.Prepare(ctx, "deactivate_user", `UPDATE users SET active = false WHERE username = $1`)
+	if err != nil {
+		return err
+	}
+
+	for _, username := range usernames {
+		if _, err = tx.Exec(ctx, sd.Name, username); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestTxPrepareDeactivateUsers_OK(t *testing.T) {
+	ctx := context.Background()
+	mockTx := pgxpoolgo.NewMockTx(t)
+
+	mockTx.ExpectPrepare("deactivate_user", `UPDATE users SET active = false WHERE username = $1`)
+	mockTx.ExpectExec(`UPDATE users SET active = false WHERE username = $1`).WithArgs("johndoe").
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("UPDATE", 1))
+	mockTx.ExpectExec(`UPDATE users SET active = false WHERE username = $1`).WithArgs("janedoe").
+		WillReturnResult(pgxpoolgo.NewMockCommandTag("UPDATE", 1))
+
+	err := txPrepareDeactivateUsers(ctx, mockTx, []string{"johndoe", "janedoe"})
+	assert.Nil(t, err)
+	assert.Nil(t, mockTx.ExpectationsWereMet())
+}
+
+func TestTxPrepare_Description(t *testing.T) {
+	ctx := context.Background()
+	mockTx := pgxpoolgo.NewMockTx(t)
+
+	mockTx.ExpectPrepare("get_user", `SELECT id, email FROM users WHERE username = $1 AND active = $2`).
+		WillReturnColumns("id", "email")
+
+	sd, err := mockTx.Prepare(ctx, "get_user", `SELECT id, email FROM users WHERE username = $1 AND active = $2`)
+	assert.Nil(t, err)
+	assert.Equal(t, "get_user", sd.Name)
+	assert.Equal(t, []uint32{pgtype.TextOID, pgtype.TextOID}, sd.ParamOIDs)
+	assert.Equal(t, 2, len(sd.Fields))
+	assert.Equal(t, "email", string(sd.Fields[1].Name))
+}
+
+func TestTxPrepare_NotPrepared(t *testing.T) {
+	ctx := context.Background()
+	mockTx := &pgxpoolgo.MockTx{}
+
+	mockTx.ExpectExec(`UPDATE users SET active = false WHERE username = $1`).WithArgs("johndoe")
+
+	_, err := mockTx.Exec(ctx, "deactivate_user", "johndoe")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `prepared statement "deactivate_user" does not exist`)
+	assert.NotNil(t, mockTx.ExpectationsWereMet())
+}